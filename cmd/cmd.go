@@ -1,8 +1,9 @@
-// Package cmd contains code necessary to start the github action
-// taking in arguments & setting them in variables in common package
+// Package cmd contains code necessary to start the github action (or the mtc command line)
+// taking in arguments, flags & environment variables and setting them in variables in common package
 package cmd
 
 import (
+	"io"
 	"log"
 	"os"
 	"strconv"
@@ -15,70 +16,210 @@ import (
 )
 
 // setArgs function takes in cmd line arguments
-// and sets common variables (api key / space / username / project path / master page ID / confluenceURL / only docs)
+// and sets common variables (api key / space / project path / master page ID / confluenceURL / only docs)
+// optionally followed by the username - these are the positional arguments the github action has always been run with
+// the other settings are only set with the flags (or MTC_* environment variables) of the sync subcommand
 func setArgs() bool {
 	var argLength = 7
 
-	if len(os.Args) < argLength-1 {
-		log.Println("usage: apikey space repopath masterpageID confluenceURL onlyDocs")
+	vars := os.Args[1:]
+
+	if len(vars) < argLength-1 || len(vars) > argLength {
+		log.Println("usage: apikey space repopath masterpageID confluenceURL onlyDocs [username] - " +
+			"set the other settings with their MTC_* environment variables (see mtc sync --help)")
 		return false
 	}
 
-	vars := os.Args[1:]
-
 	var err error
 
-	if len(vars) == argLength-1 {
-		common.ConfluenceAPIKey = vars[0]
-		common.ConfluenceSpace = vars[1]
+	common.ConfluenceAPIKey = vars[0]
+	common.ConfluenceSpace = vars[1]
+
+	common.ProjectPathEnv = vars[2]
+	common.ProjectPathEnv = strings.ReplaceAll(common.ProjectPathEnv, " ", "-") // replace spaces with -
 
-		common.ProjectPathEnv = vars[2]
-		common.ProjectPathEnv = strings.ReplaceAll(common.ProjectPathEnv, " ", "-") // replace spaces with -
+	common.ProjectMasterID, err = strconv.Atoi(vars[3])
+	if err != nil {
+		log.Println("masterpageID should be an int. If mtc is to be the root enter 0")
+		return false
+	}
+
+	if vars[4] != "" {
+		common.ConfluenceBaseURL = vars[4]
+	}
+
+	common.OnlyDocs, err = strconv.ParseBool(vars[5])
+	if err != nil {
+		log.Println("onlyDocs should be a bool")
+		return false
+	}
+
+	if len(vars) == argLength {
+		common.ConfluenceUsername = strings.TrimSpace(vars[6])
+	}
+
+	return true
+}
 
-		common.ProjectMasterID, err = strconv.Atoi(vars[3])
+// Start function runs the command line in os.Args and returns the exit code for the program
+// the positional arguments the github action has always been run with (see setArgs) are still accepted -
+// with the other settings read from the MTC_* environment variables of the sync subcommand
+// otherwise the first argument is the subcommand (see commands)
+func Start() int {
+	if legacyArgs(os.Args[1:]) {
+		err := parseFlags(newFlagSet(findCommand("sync"), os.Stderr), nil)
 		if err != nil {
-			log.Println("masterpageID should be an int. If mtc is to be the root enter 0")
-			return false
+			log.Printf("mtc: %v", err)
+			return exitUsage
 		}
 
-		if vars[4] != "" {
-			common.ConfluenceBaseURL = vars[4]
+		if !setArgs() {
+			return 1
 		}
 
-		common.OnlyDocs, err = strconv.ParseBool(vars[5])
-		if err != nil {
-			log.Println("onlyDocs should be a bool")
-			return false
-		}
+		return runSync(os.Stdout)
 	}
 
-	return true
+	return run(os.Args[1:], os.Stdout, os.Stderr)
 }
 
-// Start function sets argument inputs, creates confluence API client
-// and begins the process of creating confluence pages via calling
-// the node.Start method
-// if node.Start returns true, then calls node.Delete method
-// returns the exit code for the program
-func Start() int {
-	markdown.GrabAuthors = false
+// syncOptions function returns the options of a sync of the project path from the common variables
+func syncOptions() node.Options {
+	return node.Options{
+		ProjectPath:     common.ProjectPathEnv,
+		ParentID:        common.ProjectMasterID,
+		OnlyDocs:        common.OnlyDocs,
+		TitleTemplate:   common.TitleTemplate,
+		DuplicateTitles: common.DuplicateTitles,
+		Labels:          common.DefaultLabels,
+		ManifestFile:    common.ManifestFile,
+		Full:            common.Full,
+		Markdown: markdown.Options{
+			BaseURL: common.ConfluenceBaseURL,
+			Space:   common.ConfluenceSpace,
+		},
+	}
+}
 
-	if setArgs() {
-		root := node.Node{}
+// runSync function creates the confluence API client and a node.Syncer with it and begins the process of
+// creating confluence pages via calling the Syncer Start method
+// if Start returns true, then calls the Syncer Delete method
+// with common.Plan set the changes are only reported (to stdout) and with common.Prune
+// only the pages without a file are removed
+// returns the exit code for the program - non-zero if the run did not finish or more files failed than allowed
+// (see writeReport)
+func runSync(stdout io.Writer) int {
+	client, err := confluence.CreateAPIClient()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	var api node.APIClienter
+
+	switch common.ConfluenceAPIVersion {
+	case "", confluence.APIVersion1:
+		api = client
+	case confluence.APIVersion2:
+		api = confluence.NewV2Client(client)
+	default:
+		log.Printf("apiVersion should be %s or %s", confluence.APIVersion1, confluence.APIVersion2)
+		return 1
+	}
+
+	syncer := node.NewSyncer(syncOptions(), api)
+
+	var planner *node.Planner
+
+	if common.Plan || common.Prune {
+		planner = node.NewPlanner(api, client.RemovalMode)
+		syncer.SetAPIClient(planner)
+	}
 
-		client, err := confluence.CreateAPIClient()
+	client.OnThrottle(syncer.ShrinkConcurrency, syncer.GrowConcurrency)
+
+	run, requests, stop := runContexts(common.RunTimeout, common.ShutdownGrace)
+	defer stop()
+
+	syncer.SetRequestContext(requests)
+
+	started := syncer.Start(run)
+
+	if err := syncer.Aborted(); err != nil {
+		reportStopped(err, syncer.Skipped())
+		return writeReport(syncer.Report(), true)
+	}
+
+	if run.Err() != nil {
+		reportStopped(run.Err(), syncer.Skipped())
+		return writeReport(syncer.Report(), true)
+	}
+
+	if !started {
+		return writeReport(syncer.Report(), true)
+	}
+
+	if common.Prune {
+		syncer.SetAPIClient(api) // the pages were only planned - but the pages without a file are removed
+	}
+
+	err = syncer.Delete()
+	if err != nil {
+		log.Println(err)
+	}
+
+	if common.Plan {
 		if err != nil {
-			log.Println(err)
 			return 1
 		}
 
-		node.SetAPIClient(client)
+		return writePlan(planner, stdout)
+	}
+
+	report := syncer.Report()
 
-		if root.Start(common.ProjectMasterID, common.ProjectPathEnv, common.OnlyDocs) {
-			root.Delete()
-			return 0
-		}
+	if common.Prune { // the other pages were only planned - only the pages removed are reported
+		report = report.Only(node.ResultDeleted, node.ResultFailed)
 	}
 
-	return 1
+	if err != nil || common.Prune {
+		return writeReport(report, err != nil)
+	}
+
+	err = syncer.MarkSynced()
+	if err != nil {
+		log.Println(err)
+	}
+
+	err = syncer.SaveManifest()
+	if err != nil {
+		log.Println(err)
+	}
+
+	return writeReport(report, false)
+}
+
+// writePlan function prints the changes the run would have made to stdout
+// and writes them as json to the plan file if one was set
+// returns the exit code for the program
+func writePlan(planner *node.Planner, stdout io.Writer) int {
+	err := planner.Print(stdout)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	if common.PlanFile == "" {
+		return 0
+	}
+
+	err = planner.WriteJSON(common.PlanFile)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	log.Printf("plan written to [%s]", common.PlanFile)
+
+	return 0
 }
@@ -1,7 +1,66 @@
 package cmd
 
-import "testing"
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+)
 
 func TestStart(t *testing.T) {
 	Start()
 }
+
+// TestStartSync runs the action against a fake confluence
+func TestStartSync(t *testing.T) {
+	t.Setenv(githubStepSummary, "")
+	t.Setenv(githubOutput, "")
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	keepCommon(t)
+	t.Setenv(envName("requests-per-second"), "0") // the fake confluence is not rate limited - set as the action sets it
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	os.Args = []string{"mtc", "key", "SPACE", "../node/testfolder", strconv.Itoa(masterID), server.URL, "false"}
+
+	assert.Equal(t, 0, Start())
+	assert.Len(t, server.Children(masterID), 1)
+	assert.Len(t, server.Pages(), 4)
+}
+
+// TestSetArgs checks the positional arguments of the github action - six, or seven with the username
+func TestSetArgs(t *testing.T) {
+	keepCommon(t)
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	os.Args = []string{"mtc", "key", "SPACE", "my repo", "12", "", "true"}
+
+	assert.True(t, setArgs())
+	assert.Equal(t, "my-repo", common.ProjectPathEnv)
+	assert.Equal(t, 12, common.ProjectMasterID)
+	assert.True(t, common.OnlyDocs)
+
+	os.Args = append(os.Args, " user@example.com ")
+
+	assert.True(t, setArgs())
+	assert.Equal(t, "user@example.com", common.ConfluenceUsername)
+
+	os.Args = append(os.Args, "basic") // the other settings are only set with their environment variables
+
+	assert.False(t, setArgs())
+
+	os.Args = []string{"mtc", "key", "SPACE", "repo", "master", "", "false"}
+
+	assert.False(t, setArgs())
+}
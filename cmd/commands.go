@@ -0,0 +1,477 @@
+package cmd
+
+// commands - the mtc command line: subcommands with named flags
+// every flag can also be set with an MTC_* environment variable (e.g. --parent-id with MTC_PARENT_ID)
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/node"
+)
+
+const (
+	envPrefix = "MTC_" // flags are read from environment variables with this prefix when not set
+	exitUsage = 2      // the exit code for invalid flags / arguments (as the flag package uses)
+)
+
+// command is a subcommand of the mtc command line
+type command struct {
+	name    string
+	args    string // the arguments the command takes after its flags (for the help text)
+	summary string
+	flags   func(fs *flag.FlagSet)
+	run     func(args []string, stdout io.Writer) int
+}
+
+// commands function returns the subcommands of the mtc command line
+func commands() []command {
+	return []command{
+		{
+			name:    "sync",
+			summary: "create, update and remove the pages in confluence so they match the markdown in the repo",
+			flags: func(fs *flag.FlagSet) {
+				syncFlags(fs)
+				reportFlags(fs)
+			},
+			run: syncCommand,
+		},
+		{
+			name:    "plan",
+			summary: "print the changes sync would make to confluence without making them",
+			flags: func(fs *flag.FlagSet) {
+				syncFlags(fs)
+				fs.StringVar(&common.PlanFile, "plan-file", common.PlanFile, "also write the plan as json to this file")
+			},
+			run: func(args []string, stdout io.Writer) int {
+				common.Plan = true
+				return syncCommand(args, stdout)
+			},
+		},
+		{
+			name:    "prune",
+			summary: "only remove the pages (and attachments) that no longer have a file in the repo",
+			flags: func(fs *flag.FlagSet) {
+				syncFlags(fs)
+				reportFlags(fs)
+			},
+			run: func(args []string, stdout io.Writer) int {
+				common.Prune = true
+				return syncCommand(args, stdout)
+			},
+		},
+		{
+			name:    "validate",
+			summary: "check the flags and render every file a sync would create a page for without contacting confluence",
+			flags:   syncFlags,
+			run:     validateCommand,
+		},
+		{
+			name:    "render",
+			args:    "file...",
+			summary: "print the confluence storage format of markdown or swagger files",
+			run:     renderCommand,
+		},
+		{
+			name:    "version",
+			summary: "print the version of the tool",
+			run: func(args []string, stdout io.Writer) int {
+				fmt.Fprintf(stdout, "mtc %s\n", common.Version)
+				return 0
+			},
+		},
+	}
+}
+
+// findCommand function returns the subcommand with the name provided or nil
+func findCommand(name string) *command {
+	all := commands()
+
+	for index := range all {
+		if all[index].name == name {
+			return &all[index]
+		}
+	}
+
+	return nil
+}
+
+// legacyArgs function returns true if the arguments are the positional arguments
+// the github action has always passed (apikey space repopath masterpageID confluenceURL onlyDocs [username])
+func legacyArgs(args []string) bool {
+	const legacyLength = 6
+
+	return len(args) >= legacyLength && !strings.HasPrefix(args[0], "-") && findCommand(args[0]) == nil
+}
+
+// run function runs the subcommand named by the first argument with the rest of the arguments
+// and returns the exit code for the program
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 && findCommand(args[1]) != nil {
+			_ = newFlagSet(findCommand(args[1]), stdout).Parse([]string{"-h"})
+			return 0
+		}
+
+		usage(stdout)
+
+		return 0
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "mtc: unknown command %q\n\n", args[0])
+		usage(stderr)
+
+		return exitUsage
+	}
+
+	fs := newFlagSet(cmd, stderr)
+
+	err := parseFlags(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "mtc %s: %v\n", cmd.name, err)
+		return exitUsage
+	}
+
+	return cmd.run(fs.Args(), stdout)
+}
+
+// usage function writes the help text of the mtc command line
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: mtc <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "mtc <command> --help" for the flags of a command`)
+	fmt.Fprintf(w, "every flag can also be set with an %s* environment variable e.g. --parent-id with %sPARENT_ID\n",
+		envPrefix, envPrefix)
+	fmt.Fprintln(w, "the positional arguments of the github action "+
+		"(apikey space repopath masterpageID confluenceURL onlyDocs [username]) are still accepted")
+}
+
+// newFlagSet function returns the flag set of the command with its help text
+// each flag's help mentions the environment variable it can also be set with
+func newFlagSet(cmd *command, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("mtc "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Usage += " (" + envName(f.Name) + ")"
+	})
+
+	fs.Usage = func() {
+		fmt.Fprintf(output, "usage: %s\n\n%s\n", strings.TrimSpace("mtc "+cmd.name+" [flags] "+cmd.args), cmd.summary)
+
+		if cmd.flags != nil {
+			fmt.Fprintln(output, "\nflags:")
+			fs.PrintDefaults()
+		}
+	}
+
+	return fs
+}
+
+// envName function returns the environment variable a flag can be set with e.g. MTC_PARENT_ID for parent-id
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// parseFlags function parses the flags in args and then sets every flag that was not
+// in args from its environment variable (empty environment variables are ignored)
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	fs.VisitAll(func(f *flag.Flag) {
+		value := os.Getenv(envName(f.Name))
+		if err != nil || set[f.Name] || value == "" {
+			return
+		}
+
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", value, envName(f.Name), setErr)
+		}
+	})
+
+	return err
+}
+
+// listValue is a flag holding a comma separated list
+type listValue struct {
+	list *[]string
+}
+
+// String method returns the list comma separated
+func (l listValue) String() string {
+	if l.list == nil {
+		return ""
+	}
+
+	return strings.Join(*l.list, ",")
+}
+
+// Set method splits the comma separated value into the list
+func (l listValue) Set(value string) error {
+	*l.list = nil
+
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) != "" {
+			*l.list = append(*l.list, strings.TrimSpace(item))
+		}
+	}
+
+	return nil
+}
+
+// syncFlags function defines the flags for syncing a repo with confluence
+// each flag sets the same variable in the common package as the positional arguments
+func syncFlags(fs *flag.FlagSet) {
+	fs.StringVar(&common.ConfluenceAPIKey, "key", common.ConfluenceAPIKey,
+		"the confluence api key / token (required)")
+	fs.StringVar(&common.ConfluenceSpace, "space", common.ConfluenceSpace,
+		"the confluence space key (required)")
+	fs.StringVar(&common.ProjectPathEnv, "path", common.ProjectPathEnv,
+		"the folder of the repo to sync (required)")
+	fs.IntVar(&common.ProjectMasterID, "parent-id", common.ProjectMasterID,
+		"the confluence page the pages are created under - 0 to create them at the root of the space")
+	fs.StringVar(&common.ConfluenceBaseURL, "url", common.ConfluenceBaseURL,
+		"the confluence url")
+	fs.BoolVar(&common.OnlyDocs, "only-docs", common.OnlyDocs,
+		"only sync the docs folder of the repo")
+	fs.StringVar(&common.ConfluenceUsername, "username", common.ConfluenceUsername,
+		"the confluence account email - used with basic auth (the key is then a cloud api token)")
+	fs.StringVar(&common.ConfluenceAuthMethod, "auth-method", common.ConfluenceAuthMethod,
+		fmt.Sprintf("%s, %s or %s (default %s, or %s when a username is set and the url is a *.atlassian.net site)",
+			confluence.AuthBearer, confluence.AuthBasic, confluence.AuthOAuth2, confluence.AuthBearer, confluence.AuthBasic))
+	fs.Var(listValue{list: &common.DefaultLabels}, "labels",
+		"comma separated labels applied to every page the tool manages")
+	fs.StringVar(&common.RemovalMode, "removal-mode", common.RemovalMode,
+		fmt.Sprintf("how pages without a file are removed - %s, %s or %s",
+			confluence.RemoveTrash, confluence.RemovePurge, confluence.RemoveArchive))
+	fs.IntVar(&common.ArchivePageID, "archive-page-id", common.ArchivePageID,
+		"with removal-mode archive, removed pages are moved under this page instead of using the archive api")
+	fs.StringVar(&common.ConfluenceAPIVersion, "api-version", common.ConfluenceAPIVersion,
+		fmt.Sprintf("the confluence REST API to use - %s (data center and cloud) or %s (cloud only)",
+			confluence.APIVersion1, confluence.APIVersion2))
+	fs.DurationVar(&common.RequestTimeout, "request-timeout", common.RequestTimeout,
+		"how long a single confluence request may take")
+	fs.DurationVar(&common.RunTimeout, "run-timeout", common.RunTimeout,
+		"how long the whole run may take - pages in progress are finished and the run fails (0 means no limit)")
+	fs.StringVar(&common.TitleTemplate, "title-template", common.TitleTemplate,
+		fmt.Sprintf("the template page titles are made from e.g. \"{{.Repo}} / {{.Title}}\" (default titleTemplate in %s, or %q)",
+			config.FileName, config.DefaultTitleTemplate))
+	fs.StringVar(&common.DuplicateTitles, "duplicate-titles", common.DuplicateTitles,
+		fmt.Sprintf("what happens when pages would have the same title - %s, %s or %s (default duplicateTitles in %s, or %s)",
+			config.DuplicatesFail, config.DuplicatesSuffix, config.DuplicatesWarn, config.FileName, config.DuplicatesFail))
+	fs.StringVar(&common.ManifestFile, "manifest-file", common.ManifestFile,
+		"also read the manifest of the synced pages from and write it to this file (e.g. one kept by a ci cache)")
+	fs.BoolVar(&common.Full, "full", common.Full,
+		"render every page - by default only the pages of the files changed since the last synced commit are rendered")
+	fs.IntVar(&common.ConfluencePageSize, "page-size", common.ConfluencePageSize,
+		"the number of results requested per page when listing or searching confluence")
+	fs.Float64Var(&common.ConfluenceRequestsPerSecond, "requests-per-second", common.ConfluenceRequestsPerSecond,
+		"the average number of requests per second sent to confluence (0 means no limit)")
+	fs.IntVar(&common.ConfluenceRequestBurst, "request-burst", common.ConfluenceRequestBurst,
+		"the number of requests that can be sent to confluence at once before the rate applies")
+}
+
+// reportFlags function defines the flags for the report of what a sync (or prune) did with each file
+func reportFlags(fs *flag.FlagSet) {
+	fs.StringVar(&common.ReportFile, "report-file", common.ReportFile,
+		"write the report of what the run did with each file as json to this file")
+	fs.StringVar(&common.ReportMarkdownFile, "report-markdown", common.ReportMarkdownFile,
+		"write the report as markdown to this file (it is also added to $GITHUB_STEP_SUMMARY when set)")
+	fs.IntVar(&common.MaxFailures, "max-failures", common.MaxFailures,
+		"the number of files that can fail before the run exits non-zero (-1 means no limit)")
+}
+
+// validateSyncFlags function returns a problem for each sync flag that is missing or invalid
+// the credentials are only required when confluence is contacted
+func validateSyncFlags(credentials bool) []string {
+	var problems []string
+
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if credentials && common.ConfluenceAPIKey == "" {
+		problem("--key is required (or set %s)", envName("key"))
+	}
+
+	if credentials && common.ConfluenceSpace == "" {
+		problem("--space is required (or set %s)", envName("space"))
+	}
+
+	if common.ProjectPathEnv == "" {
+		problem("--path is required (or set %s)", envName("path"))
+	} else if info, err := os.Stat(common.ProjectPathEnv); err != nil || !info.IsDir() {
+		problem("--path [%s] is not a folder", common.ProjectPathEnv)
+	}
+
+	if common.ProjectMasterID < 0 || common.ArchivePageID < 0 {
+		problem("--parent-id and --archive-page-id should be page IDs (or 0)")
+	}
+
+	if _, err := confluence.NewAuthenticator(common.ConfluenceAuthMethod, common.ConfluenceBaseURL, common.ConfluenceUsername,
+		"key"); err != nil {
+		problem("--auth-method: %v", err)
+	}
+
+	switch common.RemovalMode {
+	case confluence.RemoveTrash, confluence.RemovePurge, confluence.RemoveArchive:
+	default:
+		problem("--removal-mode should be %s, %s or %s",
+			confluence.RemoveTrash, confluence.RemovePurge, confluence.RemoveArchive)
+	}
+
+	switch common.ConfluenceAPIVersion {
+	case confluence.APIVersion1, confluence.APIVersion2:
+	default:
+		problem("--api-version should be %s or %s", confluence.APIVersion1, confluence.APIVersion2)
+	}
+
+	if common.RequestTimeout <= 0 {
+		problem("--request-timeout should be a duration more than 0 e.g. %s", time.Minute)
+	}
+
+	if common.ConfluencePageSize <= 0 {
+		problem("--page-size should be more than 0")
+	}
+
+	if common.ConfluenceRequestsPerSecond < 0 {
+		problem("--requests-per-second should be 0 or more (0 means no limit)")
+	}
+
+	if common.ConfluenceRequestBurst <= 0 {
+		problem("--request-burst should be more than 0")
+	}
+
+	if common.RunTimeout < 0 {
+		problem("--run-timeout should be a duration e.g. %s (or 0 for no limit)", 30*time.Minute)
+	}
+
+	if common.TitleTemplate != "" {
+		if err := config.Default(".").SetTitleTemplate(common.TitleTemplate); err != nil {
+			problem("--title-template: %v", err)
+		}
+	}
+
+	if err := config.Default(".").SetDuplicateTitles(common.DuplicateTitles); err != nil {
+		problem("--duplicate-titles: %v", err)
+	}
+
+	if common.MaxFailures < -1 {
+		problem("--max-failures should be 0 or more (or -1 for no limit)")
+	}
+
+	return problems
+}
+
+// invalid function logs the problems with the flags of a command
+// and returns the exit code for invalid flags
+func invalid(name string, problems []string) int {
+	for index := range problems {
+		log.Printf("mtc %s: %s", name, problems[index])
+	}
+
+	return exitUsage
+}
+
+// syncCommand function runs a sync (or plan / prune) once the flags are validated
+func syncCommand(args []string, stdout io.Writer) int {
+	if len(args) > 0 {
+		return invalid("sync", []string{fmt.Sprintf("unexpected arguments %q", args)})
+	}
+
+	if problems := validateSyncFlags(true); len(problems) > 0 {
+		return invalid("sync", problems)
+	}
+
+	common.ProjectPathEnv = strings.ReplaceAll(common.ProjectPathEnv, " ", "-") // as with the positional arguments
+
+	return runSync(stdout)
+}
+
+// validateCommand function validates the flags and renders every file a sync would create a page for
+// the credentials are not required as confluence is not contacted
+func validateCommand(args []string, stdout io.Writer) int {
+	if len(args) > 0 {
+		return invalid("validate", []string{fmt.Sprintf("unexpected arguments %q", args)})
+	}
+
+	if problems := validateSyncFlags(false); len(problems) > 0 {
+		return invalid("validate", problems)
+	}
+
+	rendered, problems := node.NewSyncer(syncOptions(), nil).Validate()
+
+	for index := range problems {
+		fmt.Fprintln(stdout, problems[index])
+	}
+
+	fmt.Fprintf(stdout, "%d file(s) checked, %d problem(s)\n", rendered, len(problems))
+
+	if len(problems) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// renderCommand function prints the confluence storage format of each file in args
+func renderCommand(args []string, stdout io.Writer) int {
+	if len(args) == 0 {
+		return invalid("render", []string{"at least one file is required"})
+	}
+
+	syncer := node.NewSyncer(syncOptions(), nil)
+
+	for _, path := range args {
+		contents, err := syncer.Render(path)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		fmt.Fprintf(stdout, "%s\n", contents.Body)
+	}
+
+	return 0
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	"github.com/xiatechs/markdown-to-confluence/node"
+)
+
+// keepCommon function restores the variables in the common package the commands set once the test is done
+func keepCommon(t *testing.T) {
+	key, username, authMethod, url := common.ConfluenceAPIKey, common.ConfluenceUsername,
+		common.ConfluenceAuthMethod, common.ConfluenceBaseURL
+	space, path, masterID, onlyDocs := common.ConfluenceSpace, common.ProjectPathEnv, common.ProjectMasterID,
+		common.OnlyDocs
+	labels, removalMode, archiveID, apiVersion := common.DefaultLabels, common.RemovalMode, common.ArchivePageID,
+		common.ConfluenceAPIVersion
+	requestTimeout, runTimeout, rate := common.RequestTimeout, common.RunTimeout, common.ConfluenceRequestsPerSecond
+	plan, planFile, prune, titleTemplate := common.Plan, common.PlanFile, common.Prune, common.TitleTemplate
+	duplicateTitles, manifestFile, full := common.DuplicateTitles, common.ManifestFile, common.Full
+	reportFile, reportMarkdownFile, maxFailures := common.ReportFile, common.ReportMarkdownFile, common.MaxFailures
+	pageSize, burst := common.ConfluencePageSize, common.ConfluenceRequestBurst
+
+	t.Cleanup(func() {
+		common.ConfluenceAPIKey, common.ConfluenceUsername, common.ConfluenceAuthMethod, common.ConfluenceBaseURL = key,
+			username, authMethod, url
+		common.ConfluenceSpace, common.ProjectPathEnv, common.ProjectMasterID, common.OnlyDocs = space, path, masterID,
+			onlyDocs
+		common.DefaultLabels, common.RemovalMode, common.ArchivePageID, common.ConfluenceAPIVersion = labels,
+			removalMode, archiveID, apiVersion
+		common.RequestTimeout, common.RunTimeout, common.ConfluenceRequestsPerSecond = requestTimeout, runTimeout, rate
+		common.Plan, common.PlanFile, common.Prune, common.TitleTemplate = plan, planFile, prune, titleTemplate
+		common.DuplicateTitles, common.ManifestFile, common.Full = duplicateTitles, manifestFile, full
+		common.ReportFile, common.ReportMarkdownFile, common.MaxFailures = reportFile, reportMarkdownFile, maxFailures
+		common.ConfluencePageSize, common.ConfluenceRequestBurst = pageSize, burst
+	})
+}
+
+func TestLegacyArgs(t *testing.T) {
+	assert.True(t, legacyArgs([]string{"key", "SPACE", "repo", "0", "", "false"}))
+	assert.False(t, legacyArgs([]string{"key", "SPACE", "repo", "0", ""}))
+	assert.False(t, legacyArgs([]string{"sync", "--key", "key", "--space", "SPACE", "--path=repo"}))
+	assert.False(t, legacyArgs([]string{"--key", "key", "--space", "SPACE", "--path", "repo"}))
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitUsage, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "usage: mtc <command> [flags]")
+
+	stderr.Reset()
+
+	assert.Equal(t, exitUsage, run([]string{"publish"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "publish"`)
+
+	assert.Equal(t, 0, run([]string{"--help"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "  prune     only remove the pages")
+
+	stdout.Reset()
+
+	assert.Equal(t, 0, run([]string{"help", "plan"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "usage: mtc plan [flags]")
+	assert.Contains(t, stdout.String(), "(MTC_PLAN_FILE)")
+
+	stdout.Reset()
+
+	assert.Equal(t, 0, run([]string{"version"}, &stdout, &stderr))
+	assert.Equal(t, "mtc "+common.Version+"\n", stdout.String())
+}
+
+func TestParseFlags(t *testing.T) {
+	keepCommon(t)
+
+	t.Setenv("MTC_SPACE", "ENVSPACE")
+	t.Setenv("MTC_KEY", "envkey")
+	t.Setenv("MTC_LABELS", "docs, api")
+	t.Setenv("MTC_RUN_TIMEOUT", "")
+	t.Setenv("MTC_PAGE_SIZE", "50")
+	t.Setenv("MTC_REQUESTS_PER_SECOND", "2.5")
+
+	fs := newFlagSet(findCommand("sync"), &bytes.Buffer{})
+
+	err := parseFlags(fs, []string{"--key", "flagkey", "--run-timeout", "10m", "--only-docs", "--request-burst", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "flagkey", common.ConfluenceAPIKey) // flags win over environment variables
+	assert.Equal(t, "ENVSPACE", common.ConfluenceSpace)
+	assert.Equal(t, []string{"docs", "api"}, common.DefaultLabels)
+	assert.Equal(t, 10*time.Minute, common.RunTimeout)
+	assert.True(t, common.OnlyDocs)
+	assert.Equal(t, 50, common.ConfluencePageSize)
+	assert.Equal(t, 50, confluence.APIClientWithAuths(nil).PageSize)
+	assert.Equal(t, 2.5, common.ConfluenceRequestsPerSecond)
+	assert.Equal(t, 3, common.ConfluenceRequestBurst)
+
+	t.Setenv("MTC_PARENT_ID", "home")
+
+	err = parseFlags(newFlagSet(findCommand("sync"), &bytes.Buffer{}), nil)
+	assert.EqualError(t, err, `invalid value "home" for MTC_PARENT_ID: parse error`)
+}
+
+func TestRun_Invalid(t *testing.T) {
+	keepCommon(t)
+
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitUsage, run([]string{"sync", "--parent-id", "home"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `invalid value "home" for flag -parent-id`)
+
+	common.ConfluenceAPIKey, common.ConfluenceSpace = "", ""
+
+	assert.Equal(t, []string{
+		"--key is required (or set MTC_KEY)",
+		"--space is required (or set MTC_SPACE)",
+		"--path [missing] is not a folder",
+		"--removal-mode should be trash, purge or archive",
+		"--page-size should be more than 0",
+		"--requests-per-second should be 0 or more (0 means no limit)",
+		"--request-burst should be more than 0",
+		"--duplicate-titles: duplicate titles [rename] should be fail, suffix or warn",
+		"--max-failures should be 0 or more (or -1 for no limit)",
+	}, func() []string {
+		_ = parseFlags(newFlagSet(findCommand("sync"), &stderr), []string{"--path", "missing", "--removal-mode", "shred",
+			"--duplicate-titles", "rename", "--max-failures", "-2", "--page-size", "0", "--requests-per-second", "-1",
+			"--request-burst", "0"})
+
+		return validateSyncFlags(true)
+	}())
+
+	common.TitleTemplate = "{{.Nope}}"
+
+	assert.Contains(t, strings.Join(validateSyncFlags(false), "\n"),
+		"--title-template: title template [{{.Nope}}] is not valid")
+
+	assert.Equal(t, exitUsage, run([]string{"sync", "--key", "key", "--space", "SPACE", "--path", "../node", "extra"},
+		&stdout, &stderr))
+}
+
+func TestRun_ValidateAndRender(t *testing.T) {
+	keepCommon(t)
+
+	var stdout bytes.Buffer
+
+	assert.Equal(t, 0, run([]string{"validate", "--path", "../node/testfolder"}, &stdout, &bytes.Buffer{}))
+	assert.Equal(t, "3 file(s) checked, 0 problem(s)\n", stdout.String())
+
+	folder := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, "broken.md"), []byte("# broken\n\n<div>\n\ntext</span>\n"), 0o600))
+
+	stdout.Reset()
+
+	assert.Equal(t, 1, run([]string{"validate", "--path", folder}, &stdout, &bytes.Buffer{}))
+	assert.Contains(t, stdout.String(), "broken.md] does not render to valid confluence storage format")
+	assert.Contains(t, stdout.String(), "1 file(s) checked, 1 problem(s)\n")
+
+	stdout.Reset()
+
+	assert.Equal(t, 0, run([]string{"render", "../node/testfolder/readme.md"}, &stdout, &bytes.Buffer{}))
+	assert.Contains(t, stdout.String(), "<h1>INDEX readme</h1>")
+
+	assert.Equal(t, exitUsage, run([]string{"render"}, &stdout, &bytes.Buffer{}))
+	assert.Equal(t, 1, run([]string{"render", filepath.Join(folder, "broken.md")}, &stdout, &bytes.Buffer{}))
+}
+
+// TestRun_Prune syncs testfolder and then prunes it once a page without a file
+// has been added - only that page should be removed
+func TestRun_Prune(t *testing.T) {
+	keepCommon(t)
+	t.Setenv("GITHUB_REPOSITORY", "org/repo")
+	t.Setenv(githubStepSummary, "")
+	t.Setenv(githubOutput, "")
+
+	common.ConfluenceRequestsPerSecond = 0 // the fake confluence is not rate limited
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	args := []string{"--key", "key", "--space", "SPACE", "--path", "../node/testfolder",
+		"--parent-id", strconv.Itoa(masterID), "--url", server.URL}
+
+	assert.Equal(t, 0, run(append([]string{"sync"}, args...), &bytes.Buffer{}, &bytes.Buffer{}))
+
+	synced := server.Pages()
+
+	folder := server.Children(masterID)
+	if len(folder) != 1 {
+		t.Fatalf("expected 1 page under the master page but found %d", len(folder))
+	}
+
+	goneID := server.AddPage(folder[0].ID, "gone", "<p>gone</p>")
+
+	err := server.Client().SetProperty(context.Background(), goneID, confluence.ManagedPropertyKey,
+		confluence.ManagedProperty{Repo: "org/repo", Path: "gone.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+
+	assert.Equal(t, 0, run(append([]string{"prune", "--report-file", reportFile}, args...), &bytes.Buffer{},
+		&bytes.Buffer{}))
+
+	gone, _ := server.Page(goneID)
+	assert.Equal(t, confluencetest.StatusTrashed, gone.Status)
+	assert.Equal(t, synced, server.Pages()) // nothing else is created or updated
+
+	contents, err := os.ReadFile(filepath.Clean(reportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report node.Report
+
+	assert.NoError(t, json.Unmarshal(contents, &report))
+	assert.Equal(t, []node.FileResult{{File: "gone.md", Result: node.ResultDeleted, Title: "gone", PageID: goneID}},
+		report.Files) // only the pages removed are reported
+}
+
+// TestRun_Prune_Retitled syncs a repo and then prunes it once a file has been given a new title
+// - the page of the file is still synced from it so nothing should be removed
+func TestRun_Prune_Retitled(t *testing.T) {
+	keepCommon(t)
+	t.Setenv("GITHUB_REPOSITORY", "org/repo")
+	t.Setenv(githubStepSummary, "")
+	t.Setenv(githubOutput, "")
+
+	common.ConfluenceRequestsPerSecond = 0 // the fake confluence is not rate limited
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	repo := t.TempDir()
+
+	write := func(name, contents string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("readme.md", "# Docs\n\nthe docs\n")
+	write("guide/readme.md", "# Guide\n\nthe guide\n")
+	write("guide/hello.md", "# Hello\n\nhello\n")
+
+	args := []string{"--key", "key", "--space", "SPACE", "--path", repo,
+		"--parent-id", strconv.Itoa(masterID), "--url", server.URL}
+
+	assert.Equal(t, 0, run(append([]string{"sync"}, args...), &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.Len(t, server.Pages(), 4)
+
+	synced := server.Pages()
+
+	write("guide/hello.md", "# Hello Again\n\nhello\n")
+
+	requests := len(server.Requests())
+
+	assert.Equal(t, 0, run(append([]string{"prune"}, args...), &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.Equal(t, synced, server.Pages()) // the retitled page is kept
+
+	for _, request := range server.Requests()[requests:] {
+		assert.True(t, strings.HasPrefix(request, "GET "), request)
+	}
+}
+
+// TestRun_Report syncs testfolder with the report files set in a github action step
+// and checks the report, the step summary and the outputs
+func TestRun_Report(t *testing.T) {
+	keepCommon(t)
+
+	common.ConfluenceRequestsPerSecond = 0 // the fake confluence is not rate limited
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	folder := t.TempDir()
+	reportFile, markdownFile := filepath.Join(folder, "report.json"), filepath.Join(folder, "report.md")
+	summaryFile, outputFile := filepath.Join(folder, "summary.md"), filepath.Join(folder, "output")
+
+	t.Setenv(githubStepSummary, summaryFile)
+	t.Setenv(githubOutput, outputFile)
+
+	assert.Equal(t, 0, run([]string{"sync", "--key", "key", "--space", "SPACE", "--path", "../node/testfolder",
+		"--parent-id", strconv.Itoa(masterID), "--url", server.URL, "--report-file", reportFile,
+		"--report-markdown", markdownFile}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	contents, err := os.ReadFile(filepath.Clean(reportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report node.Report
+
+	assert.NoError(t, json.Unmarshal(contents, &report))
+	assert.Equal(t, map[string]int{node.ResultCreated: 4}, report.Counts)
+
+	root, _ := server.PageByTitle("INDEX readme (node/testfolder)")
+	rootURL := server.URL + "/spaces/SPACE/pages/" + strconv.Itoa(root.ID)
+
+	markdown, err := os.ReadFile(filepath.Clean(markdownFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := os.ReadFile(filepath.Clean(summaryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(markdown), "| readme.md | created | [INDEX readme (node/testfolder)]("+rootURL+") |  |")
+	assert.Equal(t, string(markdown), string(summary))
+
+	output, err := os.ReadFile(filepath.Clean(outputFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, string(output), `"readme.md":"`+rootURL+`"`)
+	assert.Contains(t, string(output), "\nrootPageURL="+rootURL+"\nfailed=0\n")
+}
+
+// TestWriteReport_Markdown checks the markdown report file is replaced by each run
+// while the report is added to the end of the github step summary
+func TestWriteReport_Markdown(t *testing.T) {
+	keepCommon(t)
+
+	dir := t.TempDir()
+	common.ReportMarkdownFile = filepath.Join(dir, "report.md")
+	summaryFile := filepath.Join(dir, "summary.md")
+
+	t.Setenv(githubStepSummary, summaryFile)
+	t.Setenv(githubOutput, "")
+
+	for _, file := range []string{common.ReportMarkdownFile, summaryFile} {
+		if err := os.WriteFile(file, []byte("earlier\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := node.Report{Repo: "org/repo", Counts: map[string]int{}}
+
+	assert.Equal(t, 0, writeReport(report, false))
+	assert.Equal(t, 0, writeReport(report, false))
+
+	var expected bytes.Buffer
+
+	assert.NoError(t, report.PrintMarkdown(&expected))
+
+	markdown, err := os.ReadFile(filepath.Clean(common.ReportMarkdownFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := os.ReadFile(filepath.Clean(summaryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, expected.String(), string(markdown))
+	assert.Equal(t, "earlier\n"+expected.String()+expected.String(), string(summary))
+}
+
+func TestWriteReport(t *testing.T) {
+	keepCommon(t)
+	t.Setenv(githubStepSummary, "")
+	t.Setenv(githubOutput, "")
+
+	report := node.Report{Counts: map[string]int{node.ResultFailed: 2}, Files: []node.FileResult{
+		{File: "a.md", Result: node.ResultFailed, Error: "a failed"},
+		{File: "b.md", Result: node.ResultFailed, Error: "b failed"},
+	}}
+
+	common.MaxFailures = 0
+	assert.Equal(t, 1, writeReport(report, false))
+	assert.Equal(t, 0, writeReport(node.Report{Counts: map[string]int{}}, false))
+	assert.Equal(t, 1, writeReport(node.Report{Counts: map[string]int{}}, true)) // the run did not finish
+
+	common.MaxFailures = 2
+	assert.Equal(t, 0, writeReport(report, false))
+
+	common.MaxFailures = -1
+	assert.Equal(t, 0, writeReport(report, false))
+}
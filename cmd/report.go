@@ -0,0 +1,123 @@
+package cmd
+
+// report - writing the report of what a run did with each file (as json, markdown and to the github step summary)
+// and setting the github action outputs from it
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/node"
+)
+
+const (
+	githubStepSummary = "GITHUB_STEP_SUMMARY" // the file github shows as the summary of the step
+	githubOutput      = "GITHUB_OUTPUT"       // the file the outputs of the step are set in
+)
+
+// writeReport function logs the report and writes it to the report files, the github step summary and outputs
+// (nothing is written with common.Plan set - the plan is printed instead)
+// returns the exit code for the program: 1 if the run failed or more files failed than common.MaxFailures
+func writeReport(report node.Report, failed bool) int {
+	if common.Plan {
+		if failed {
+			return 1
+		}
+
+		return 0
+	}
+
+	log.Printf("report: %s", report.Summary())
+
+	for _, result := range report.Files {
+		if result.Result == node.ResultFailed {
+			log.Printf("  failed: %s - %s", result.File, result.Error)
+		}
+	}
+
+	code := 0
+
+	if failed {
+		code = 1
+	}
+
+	if common.MaxFailures >= 0 && report.Failed() > common.MaxFailures {
+		log.Printf("[%d] file(s) failed - more than the [%d] allowed (--max-failures)", report.Failed(), common.MaxFailures)
+
+		code = 1
+	}
+
+	if common.ReportFile != "" {
+		if err := report.WriteJSON(common.ReportFile); err != nil {
+			log.Println(err)
+		} else {
+			log.Printf("report written to [%s]", common.ReportFile)
+		}
+	}
+
+	if common.ReportMarkdownFile != "" {
+		if err := writeMarkdownReport(report, common.ReportMarkdownFile, os.O_TRUNC); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if file := os.Getenv(githubStepSummary); file != "" {
+		if err := writeMarkdownReport(report, file, os.O_APPEND); err != nil {
+			log.Println(err)
+		}
+	}
+
+	if file := os.Getenv(githubOutput); file != "" {
+		if err := setOutputs(report, file); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return code
+}
+
+// writeMarkdownReport function writes the report as markdown to the file (creating it if need be)
+// mode is os.O_TRUNC to replace what is in the file or os.O_APPEND to add the report to the end of it
+// (the github step summary is shared by every step of the job so the report is added to it)
+func writeMarkdownReport(report node.Report, file string, mode int) error {
+	f, err := os.OpenFile(filepath.Clean(file), mode|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("write markdown report error: %w", err)
+	}
+
+	err = report.PrintMarkdown(f)
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("write markdown report error: %w", err)
+	}
+
+	return f.Close()
+}
+
+// setOutputs function sets the github action outputs in the file: pages (the url of the page of each file as json),
+// rootPageURL and failed (the number of files that failed)
+func setOutputs(report node.Report, file string) error {
+	pages, err := json.Marshal(report.Pages())
+	if err != nil {
+		return fmt.Errorf("pages output json marshal error: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(file), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("write github outputs error: %w", err)
+	}
+
+	_, err = fmt.Fprintf(f, "pages=%s\nrootPageURL=%s\nfailed=%d\n", pages, report.RootPageURL, report.Failed())
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("write github outputs error: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,81 @@
+package cmd
+
+// shutdown - stopping a run early on SIGINT / SIGTERM or when the run deadline passes
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runContexts function returns the contexts for a run:
+// run is done on the first SIGINT / SIGTERM or once the run timeout passes, so no new files are started
+// requests is done on a second signal or once the shutdown grace has passed, abandoning requests in flight
+// stop must be called once the run is finished
+func runContexts(runTimeout, grace time.Duration) (run, requests context.Context, stop func()) {
+	var cancelRun context.CancelFunc
+
+	if runTimeout > 0 {
+		run, cancelRun = context.WithTimeout(context.Background(), runTimeout)
+	} else {
+		run, cancelRun = context.WithCancel(context.Background())
+	}
+
+	requests, cancelRequests := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2) //nolint:gomnd // graceful then immediate
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("received [%s] - finishing the pages in progress (send it again to stop immediately)", sig)
+			cancelRun()
+		case <-run.Done():
+			if run.Err() != context.DeadlineExceeded {
+				return
+			}
+
+			log.Printf("the run took longer than [%s] - finishing the pages in progress", runTimeout)
+		case <-done:
+			return
+		}
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+
+		select {
+		case sig := <-signals:
+			log.Printf("received [%s] again - abandoning the requests in progress", sig)
+		case <-timer.C:
+			log.Printf("requests in progress did not finish within [%s] - abandoning them", grace)
+		case <-done:
+			return
+		}
+
+		cancelRequests()
+	}()
+
+	return run, requests, func() {
+		signal.Stop(signals)
+		close(done)
+		cancelRun()
+		cancelRequests()
+	}
+}
+
+// reportStopped function logs the files and folders that were not synced because the run was stopped
+func reportStopped(err error, skipped []string) {
+	log.Printf("the run was stopped before it finished (%v) - [%d] file(s) / folder(s) were not synced:", err, len(skipped))
+
+	for index := range skipped {
+		log.Printf("  not synced: %s", skipped[index])
+	}
+
+	log.Println("pages without a file in the repo were not removed as the run did not finish")
+}
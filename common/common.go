@@ -1,7 +1,13 @@
 // Package common is for storing common constants/vars used in app
 package common
 
+import "time"
+
 var (
+	// Version is the version of the tool - stored on every page it manages
+	// set at build time with -ldflags "-X github.com/xiatechs/markdown-to-confluence/common.Version=..."
+	Version = "dev"
+
 	// ConfluenceBaseURL is the base URL for the confluence page you want the API to connect to
 	// by default it is https://xiatech.atlassian.net but can be changed below
 	ConfluenceBaseURL = "https://xiatech-markup.atlassian.net"
@@ -9,6 +15,16 @@ var (
 	// ConfluenceAPIKey is to collect external arg for api key
 	ConfluenceAPIKey string
 
+	// ConfluenceUsername is to collect external arg for the account email used with basic auth
+	ConfluenceUsername string
+
+	// ConfluenceAuthMethod is to collect external arg for the auth method (bearer / basic / oauth2)
+	// if left empty basic auth is used when a username is set, otherwise bearer
+	ConfluenceAuthMethod string
+
+	// ConfluenceAPIVersion is the confluence REST API the tool uses - v1 (default) or v2 (cloud only)
+	ConfluenceAPIVersion = "v1"
+
 	// ConfluenceSpace is to collect external arg for confluence space
 	ConfluenceSpace string
 
@@ -18,6 +34,73 @@ var (
 	// ProjectMasterID is to collect external arg for the correct parent page ID
 	ProjectMasterID int
 
+	// ConfluencePageSize is the number of results requested per page when listing/searching confluence
+	ConfluencePageSize = 25
+
+	// ConfluenceRequestsPerSecond is the average number of requests per second shared by all workers
+	// (0 means no limit other than what confluence tells us via 429 / Retry-After)
+	ConfluenceRequestsPerSecond = 10.0
+
+	// ConfluenceRequestBurst is the number of requests that can be sent at once before the rate applies
+	ConfluenceRequestBurst = 10
+
+	// DefaultLabels are labels applied to every page managed by the tool
+	// alongside any labels/tags set in a file's frontmatter
+	DefaultLabels []string
+
+	// RemovalMode is how pages that no longer have a file are removed - trash (default), purge or archive
+	RemovalMode = "trash"
+
+	// ArchivePageID is the page removed pages are moved under when RemovalMode is archive
+	// if 0 the confluence cloud archive api is used instead
+	ArchivePageID int
+
+	// RequestTimeout is how long a single request to confluence may take before it is abandoned
+	RequestTimeout = 60 * time.Second
+
+	// RunTimeout is how long the whole run may take before no new files are started (0 means no limit)
+	// requests already in flight are given ShutdownGrace to finish
+	RunTimeout time.Duration
+
+	// ShutdownGrace is how long requests in flight are given to finish once the run is stopped
+	ShutdownGrace = 30 * time.Second
+
+	// Plan is a flag to only report the changes a run would make to confluence without making them
+	Plan bool
+
+	// PlanFile is the file the plan is also written to as json when Plan is set (optional)
+	PlanFile string
+
+	// Prune is a flag to only remove the pages (and attachments) that no longer have a file in the repo
+	// without creating or updating any other page
+	Prune bool
+
 	// OnlyDocs is a flag to decide whether it is only the /docs folder to copy across
 	OnlyDocs bool
+
+	// TitleTemplate is the template page titles are made from e.g. "{{.Repo}} / {{.Title}}"
+	// if empty the titleTemplate of the repo config (.mtc.yaml) is used
+	TitleTemplate string
+
+	// DuplicateTitles is what a sync does when pages would have the same title - fail, suffix or warn
+	// if empty the duplicateTitles of the repo config (.mtc.yaml) is used
+	DuplicateTitles string
+
+	// ManifestFile is a file the manifest of the synced pages is also read from and written to (optional)
+	// the manifest is always kept on the root page of the repo - the file lets a ci cache keep it too
+	ManifestFile string
+
+	// Full is a flag to render every page - by default only the pages of the files changed
+	// since the last synced commit are rendered (and the pages linking to files added, removed or renamed since)
+	Full bool
+
+	// ReportFile is the file the report of what the run did with each file is written to as json (optional)
+	ReportFile string
+
+	// ReportMarkdownFile is the file the report is written to as markdown (optional)
+	// the report is also added to the github step summary ($GITHUB_STEP_SUMMARY) when there is one
+	ReportMarkdownFile string
+
+	// MaxFailures is the number of files that can fail before the run exits non-zero (-1 means no limit)
+	MaxFailures int
 )
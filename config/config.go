@@ -0,0 +1,315 @@
+// Package config is for the optional .mtc.yaml repository configuration file
+// which decides which folders and files of a repo are published to confluence
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file read from the root of the repo
+const FileName = ".mtc.yaml"
+
+var (
+	defaultExclude = []string{"vendor", ".git", ".github"}
+	defaultPages   = []string{".md", ".swagger.json"}
+	defaultImages  = []string{".png", ".jpg", ".jpeg", ".gif"}
+)
+
+// Config is the configuration of a repo - every path it is asked about is a path on disk
+// below the root of the repo (the project path the tool was started with)
+type Config struct {
+	// Include are glob patterns of the files to publish - if empty every file is published
+	Include []string `yaml:"include"`
+
+	// Exclude are glob patterns of the files and folders never published (default vendor, .git and .github)
+	// the .git folder is always excluded
+	Exclude []string `yaml:"exclude"`
+
+	// Gitignore is whether the files and folders ignored by the .gitignore in the root of the repo
+	// are left out too (default true)
+	Gitignore *bool `yaml:"gitignore"`
+
+	// OnlyDocs is whether only the root of the repo and docs folders are published (as the onlyDocs flag)
+	OnlyDocs bool `yaml:"onlyDocs"`
+
+	// FileTypes are the file extensions published as pages and uploaded as images
+	FileTypes FileTypes `yaml:"fileTypes"`
+
+	// Paths are settings for the files and folders matching a glob pattern
+	Paths []PathSettings `yaml:"paths"`
+
+	// TitleTemplate is the text/template page titles are made from (see TitleFields) - default DefaultTitleTemplate
+	TitleTemplate string `yaml:"titleTemplate"`
+
+	// DuplicateTitles is what a sync does when pages would have the same title - fail (default), suffix or warn
+	DuplicateTitles string `yaml:"duplicateTitles"`
+
+	root    string
+	include []rule
+	exclude []rule
+	ignore  []rule
+	title   *template.Template
+}
+
+// FileTypes are the file extensions (e.g. .md) the tool publishes
+type FileTypes struct {
+	Pages  []string `yaml:"pages"`  // published as pages (.swagger.json files are rendered from swagger)
+	Images []string `yaml:"images"` // uploaded as attachments to the page of their folder
+}
+
+// PathSettings are settings for the files and folders matching the Match glob pattern
+// where several match a path their labels are combined and the last plantuml setting is used
+type PathSettings struct {
+	Match    string   `yaml:"match"`
+	Labels   []string `yaml:"labels"`   // added to the pages of the matching files and folders
+	Plantuml *bool    `yaml:"plantuml"` // whether diagrams of go code are generated in the matching folders
+
+	rule rule
+}
+
+// Settings are the settings for a single path
+type Settings struct {
+	Labels   []string
+	Plantuml bool
+}
+
+// Default function returns the configuration used when a repo has no configuration file
+// (without the .gitignore of the repo - see Load)
+func Default(root string) *Config {
+	config := &Config{}
+
+	_ = config.compile(root) // the default patterns always compile
+
+	return config
+}
+
+// Load function reads the configuration file from the root of the repo, if there is one
+// onlyDocs is the onlyDocs flag - either it or the configuration file can limit the repo to docs folders
+func Load(root string, onlyDocs bool) (*Config, error) {
+	config := &Config{}
+
+	contents, err := os.ReadFile(filepath.Join(root, FileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load config error: %w", err)
+	}
+
+	if len(contents) > 0 {
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+
+		err = decoder.Decode(config)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("load config error: [%s] is not valid: %w", FileName, err)
+		}
+	}
+
+	config.OnlyDocs = config.OnlyDocs || onlyDocs
+
+	err = config.compile(root)
+	if err != nil {
+		return nil, fmt.Errorf("load config error: [%s] is not valid: %w", FileName, err)
+	}
+
+	if config.Gitignore == nil || *config.Gitignore {
+		config.ignore, err = loadGitignore(filepath.Join(root, ".gitignore"))
+		if err != nil {
+			return nil, fmt.Errorf("load config error: %w", err)
+		}
+	}
+
+	return config, nil
+}
+
+// compile method applies the defaults and compiles the glob patterns of the configuration
+func (c *Config) compile(root string) error {
+	var err error
+
+	c.root = root
+
+	if c.Exclude == nil {
+		c.Exclude = append([]string{}, defaultExclude...)
+	}
+
+	if len(c.FileTypes.Pages) == 0 {
+		c.FileTypes.Pages = append([]string{}, defaultPages...)
+	}
+
+	if c.FileTypes.Images == nil {
+		c.FileTypes.Images = append([]string{}, defaultImages...)
+	}
+
+	for _, extensions := range [][]string{c.FileTypes.Pages, c.FileTypes.Images} {
+		for index := range extensions {
+			if !strings.HasPrefix(extensions[index], ".") {
+				return fmt.Errorf("file type [%s] should be an extension starting with '.'", extensions[index])
+			}
+
+			extensions[index] = strings.ToLower(extensions[index])
+		}
+	}
+
+	c.include, err = compileRules(c.Include)
+	if err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+
+	c.exclude, err = compileRules(append([]string{".git"}, c.Exclude...))
+	if err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+
+	err = c.SetTitleTemplate(c.TitleTemplate)
+	if err != nil {
+		return err
+	}
+
+	err = c.SetDuplicateTitles(c.DuplicateTitles)
+	if err != nil {
+		return err
+	}
+
+	for index := range c.Paths {
+		if c.Paths[index].Match == "" {
+			return fmt.Errorf("paths: every path needs a match pattern")
+		}
+
+		c.Paths[index].rule, err = newRule(c.Paths[index].Match)
+		if err != nil {
+			return fmt.Errorf("paths: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// compileRules function compiles each glob pattern into a rule
+func compileRules(patterns []string) ([]rule, error) {
+	rules := make([]rule, 0, len(patterns))
+
+	for index := range patterns {
+		rule, err := newRule(patterns[index])
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// relative method returns the path relative to the root of the repo with / separators
+func (c *Config) relative(fpath string) string {
+	rel, err := filepath.Rel(c.root, fpath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return filepath.ToSlash(filepath.Clean(fpath))
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// Folder method returns true if the files in the folder (and its sub folders) can be published
+// i.e. neither the folder nor a folder above it is excluded or ignored
+// and, with OnlyDocs, the folder is the root of the repo or in a docs folder
+func (c *Config) Folder(fpath string) bool {
+	return c.folder(c.relative(fpath))
+}
+
+// folder method is Folder for a path relative to the root of the repo
+func (c *Config) folder(rel string) bool {
+	if rel == "." {
+		return true
+	}
+
+	if c.OnlyDocs && !inDocs(rel) {
+		return false
+	}
+
+	for dir := rel; dir != "."; dir = path.Dir(dir) {
+		if matchAny(c.exclude, dir, true) || ignored(c.ignore, dir, true) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inDocs function returns true if a folder in the path is called docs
+func inDocs(rel string) bool {
+	for _, folder := range strings.Split(rel, "/") {
+		if folder == "docs" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// file method returns true if the file is in a folder that can be published
+// and is included and neither excluded nor ignored
+func (c *Config) file(fpath string) bool {
+	rel := c.relative(fpath)
+
+	if !c.folder(path.Dir(rel)) || matchAny(c.exclude, rel, false) || ignored(c.ignore, rel, false) {
+		return false
+	}
+
+	return len(c.include) == 0 || matchAny(c.include, rel, false)
+}
+
+// Page method returns true if the file is published as a page
+func (c *Config) Page(fpath string) bool {
+	return hasExtension(fpath, c.FileTypes.Pages) && c.file(fpath)
+}
+
+// Image method returns true if the file is uploaded as an image
+func (c *Config) Image(fpath string) bool {
+	return hasExtension(fpath, c.FileTypes.Images) && c.file(fpath)
+}
+
+// hasExtension function returns true if the file name ends with one of the extensions (ignoring case)
+func hasExtension(fpath string, extensions []string) bool {
+	name := strings.ToLower(filepath.Base(fpath))
+
+	for index := range extensions {
+		if strings.HasSuffix(name, extensions[index]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Settings method returns the settings of every path setting matching the file or folder
+func (c *Config) Settings(fpath string) Settings {
+	rel := c.relative(fpath)
+
+	info, err := os.Stat(fpath)
+	dir := err == nil && info.IsDir()
+
+	settings := Settings{Plantuml: true}
+
+	for index := range c.Paths {
+		if !c.Paths[index].rule.match(rel, dir) {
+			continue
+		}
+
+		settings.Labels = append(settings.Labels, c.Paths[index].Labels...)
+
+		if c.Paths[index].Plantuml != nil {
+			settings.Plantuml = *c.Paths[index].Plantuml
+		}
+	}
+
+	return settings
+}
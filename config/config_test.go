@@ -0,0 +1,196 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeRepo function writes the files (path: contents) into a temporary repo and returns its root
+func writeRepo(t *testing.T, files map[string]string) string {
+	root := filepath.Join(t.TempDir(), "repo")
+
+	for path, contents := range files {
+		err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o700)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filepath.Join(root, path), []byte(contents), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	root := writeRepo(t, map[string]string{"readme.md": ""})
+
+	config, err := Load(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{
+		"readme.md":                 true,
+		"docs/vendoring.md":         true, // only folders called vendor are excluded
+		"docs/api.swagger.json":     true,
+		"docs/IMAGE.PNG":            false, // an image rather than a page
+		"vendor/lib/readme.md":      false,
+		"src/vendor/lib/readme.md":  false,
+		".github/workflows/docs.md": false,
+		"main.go":                   false,
+	} {
+		assert.Equal(t, want, config.Page(filepath.Join(root, path)), path)
+	}
+
+	assert.True(t, config.Image(filepath.Join(root, "docs/IMAGE.PNG")))
+	assert.True(t, config.Folder(root))
+	assert.Equal(t, DuplicatesFail, config.DuplicateTitles)
+	assert.True(t, config.Folder(filepath.Join(root, "docs/vendoring")))
+	assert.False(t, config.Folder(filepath.Join(root, "src/vendor")))
+	assert.Equal(t, Settings{Plantuml: true}, config.Settings(filepath.Join(root, "readme.md")))
+}
+
+func TestLoad_OnlyDocs(t *testing.T) {
+	root := writeRepo(t, map[string]string{"readme.md": ""})
+
+	config, err := Load(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, config.Folder(root))
+	assert.True(t, config.Folder(filepath.Join(root, "docs")))
+	assert.True(t, config.Folder(filepath.Join(root, "pkg/docs/guides")))
+	assert.False(t, config.Folder(filepath.Join(root, "src")))
+	assert.False(t, config.Folder(filepath.Join(root, "docsite"))) // not a substring match
+	assert.True(t, config.Page(filepath.Join(root, "readme.md")))
+	assert.False(t, config.Page(filepath.Join(root, "src/readme.md")))
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	root := writeRepo(t, map[string]string{
+		FileName: `
+include: ["/readme.md", "docs/**"]
+exclude: ["*.draft.md", "**/testdata/**"]
+fileTypes:
+  pages: [".md", ".MARKDOWN"]
+  images: [".svg"]
+paths:
+  - match: "docs/runbooks/**"
+    labels: [runbook]
+    plantuml: false
+  - match: "docs/runbooks/db/"
+    labels: [database]
+`,
+		".gitignore": "# build output\n/docs/generated/\n*.tmp.md\n!keep.tmp.md\n",
+	})
+
+	config, err := Load(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{
+		"readme.md":                   true,
+		"src/readme.md":               false, // not included
+		"docs/guide.md":               true,
+		"docs/guide.markdown":         true,
+		"docs/guide.draft.md":         false,
+		"docs/testdata/sample.md":     false,
+		"docs/api.swagger.json":       false, // not a page file type
+		"docs/generated/reference.md": false, // ignored by .gitignore
+		"docs/notes.tmp.md":           false,
+		"docs/keep.tmp.md":            true,
+		"vendor/readme.md":            false, // not included
+	} {
+		assert.Equal(t, want, config.Page(filepath.Join(root, path)), path)
+	}
+
+	assert.True(t, config.Folder(filepath.Join(root, "vendor"))) // setting exclude replaces the default patterns
+	assert.False(t, config.Folder(filepath.Join(root, ".git")))
+	assert.False(t, config.Folder(filepath.Join(root, "docs/testdata")))
+	assert.True(t, config.Image(filepath.Join(root, "docs/diagram.svg")))
+	assert.False(t, config.Image(filepath.Join(root, "docs/diagram.png")))
+
+	assert.Equal(t, Settings{Labels: []string{"runbook"}, Plantuml: false},
+		config.Settings(filepath.Join(root, "docs/runbooks/restart.md")))
+	assert.Equal(t, Settings{Plantuml: true}, config.Settings(filepath.Join(root, "docs/guide.md")))
+
+	err = os.MkdirAll(filepath.Join(root, "docs/runbooks/db"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Settings{Labels: []string{"runbook", "database"}, Plantuml: false},
+		config.Settings(filepath.Join(root, "docs/runbooks/db")))
+}
+
+func TestLoad_Gitignore(t *testing.T) {
+	root := writeRepo(t, map[string]string{
+		FileName:     "gitignore: false\n",
+		".gitignore": "docs/\n",
+	})
+
+	config, err := Load(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, config.Page(filepath.Join(root, "docs/guide.md")))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, contents := range map[string]string{
+		"unknown setting": "includes: [docs]\n",
+		"bad glob":        "exclude: [\"docs/[\"]\n",
+		"bad file type":   "fileTypes:\n  pages: [md]\n",
+		"no match":        "paths:\n  - labels: [docs]\n",
+		"bad duplicates":  "duplicateTitles: rename\n",
+	} {
+		root := writeRepo(t, map[string]string{FileName: contents})
+
+		_, err := Load(root, false)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	fields := TitleFields{Title: "Setup", Dir: "repo/docs", Repo: "owner/repo", Path: "docs/setup.md"}
+
+	config := Default(".")
+
+	title, err := config.Title(fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "Setup (repo/docs)", title)
+
+	root := writeRepo(t, map[string]string{FileName: "titleTemplate: \"{{.Repo}} / {{.Title}}\"\n"})
+
+	config, err = Load(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title, err = config.Title(fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "owner/repo / Setup", title)
+
+	assert.NoError(t, config.SetTitleTemplate("{{.Title}}  \n"))
+
+	title, err = config.Title(fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "Setup", title)
+
+	assert.Error(t, config.SetTitleTemplate("{{.Title"))
+	assert.Error(t, config.SetTitleTemplate("{{.Name}}"))
+	assert.Error(t, config.SetTitleTemplate("{{if false}}{{end}}"))
+	assert.Equal(t, "{{.Title}}  \n", config.TitleTemplate) // a template that is not valid is not set
+
+	_, err = Load(writeRepo(t, map[string]string{FileName: "titleTemplate: \"{{.Nope}}\"\n"}), false)
+	assert.Error(t, err)
+}
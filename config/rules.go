@@ -0,0 +1,160 @@
+package config
+
+// rules - glob patterns matched against paths relative to the root of the repo (as .gitignore matches them)
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// rule is a compiled glob pattern:
+// a pattern without a / (e.g. vendor or *.draft.md) matches the name of a file or folder anywhere in the repo
+// a pattern with a / (e.g. docs/** or /notes.md) matches the path from the root of the repo
+// where * does not match / and ** matches any number of folders (including none with a leading **/)
+// a pattern ending with / only matches folders
+type rule struct {
+	pattern  string
+	globs    []glob.Glob
+	anchored bool // matched against the path from the root rather than the name
+	dirOnly  bool // only matches folders
+	negate   bool // a .gitignore pattern starting with ! - the path is not ignored
+}
+
+// newRule function compiles a glob pattern into a rule
+func newRule(pattern string) (rule, error) {
+	r := rule{pattern: pattern}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	if strings.Contains(pattern, "/") {
+		r.anchored = true
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	if pattern == "" {
+		return r, fmt.Errorf("pattern [%s] is empty", r.pattern)
+	}
+
+	patterns := []string{pattern}
+	if strings.HasPrefix(pattern, "**/") {
+		patterns = append(patterns, strings.TrimPrefix(pattern, "**/")) // **/ matches no folders too
+	}
+
+	for index := range patterns {
+		compiled, err := glob.Compile(patterns[index], '/')
+		if err != nil {
+			return r, fmt.Errorf("pattern [%s] is not a valid glob: %w", r.pattern, err)
+		}
+
+		r.globs = append(r.globs, compiled)
+	}
+
+	return r, nil
+}
+
+// match method returns true if the rule matches the path (relative to the root of the repo)
+// a folder is also matched with a trailing / so a pattern for everything in a folder (e.g. docs/**) matches the folder
+func (r rule) match(rel string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+
+	candidates := []string{path.Base(rel)}
+
+	if r.anchored {
+		candidates = []string{rel}
+
+		if dir {
+			candidates = append(candidates, rel+"/")
+		}
+	}
+
+	for _, compiled := range r.globs {
+		for _, candidate := range candidates {
+			if compiled.Match(candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchAny function returns true if any of the rules match the path
+func matchAny(rules []rule, rel string, dir bool) bool {
+	for index := range rules {
+		if rules[index].match(rel, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignored function returns true if the .gitignore rules ignore the path
+// the last rule matching the path decides, so a ! rule can un-ignore a path ignored by an earlier rule
+func ignored(rules []rule, rel string, dir bool) bool {
+	var ignore bool
+
+	for index := range rules {
+		if rules[index].match(rel, dir) {
+			ignore = !rules[index].negate
+		}
+	}
+
+	return ignore
+}
+
+// loadGitignore function reads the rules of a .gitignore file - there are none if the file does not exist
+func loadGitignore(fpath string) ([]rule, error) {
+	file, err := os.Open(filepath.Clean(fpath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read gitignore error: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var rules []rule
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negate := strings.HasPrefix(line, "!")
+
+		r, err := newRule(strings.TrimPrefix(line, "!"))
+		if err != nil {
+			continue // patterns git accepts but the glob library does not are left out rather than failing the run
+		}
+
+		r.negate = negate
+
+		rules = append(rules, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read gitignore error: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,104 @@
+package config
+
+// title - the template page titles are made from, so pages with the same title in different folders
+// (e.g. every folder's readme) do not clash in the confluence space
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultTitleTemplate is the title template used when none is set - the title followed by the folder
+const DefaultTitleTemplate = "{{.Title}} ({{.Dir}})"
+
+// what a sync does when pages would have the same title - as each other or as a page in the space it does not manage
+const (
+	DuplicatesFail   = "fail"   // stop before anything is changed and report the duplicate titles
+	DuplicatesSuffix = "suffix" // add the repo and path of the file to the duplicate titles e.g. "Setup [owner/repo docs/setup.md]"
+	DuplicatesWarn   = "warn"   // report the duplicate titles and carry on
+)
+
+// TitleFields are the values a title template can use
+type TitleFields struct {
+	Title string // the frontmatter title, first # heading or file name - or the folder name for folder pages
+	Dir   string // the folder of the page from the repo folder e.g. repo/docs/guides
+	Repo  string // the repo the page is managed for e.g. owner/repo
+	Path  string // the file (or folder) the page is for from the root of the repo e.g. docs/guides/setup.md
+}
+
+// SetTitleTemplate method parses the template page titles are made from e.g. "{{.Repo}} / {{.Title}}"
+// an empty template sets the default template
+func (c *Config) SetTitleTemplate(text string) error {
+	if text == "" {
+		text = DefaultTitleTemplate
+	}
+
+	parsed, err := template.New("title").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("title template [%s] is not valid: %w", text, err)
+	}
+
+	_, err = execute(parsed, TitleFields{Title: "title", Dir: "repo/docs", Repo: "owner/repo", Path: "docs/title.md"})
+	if err != nil {
+		return fmt.Errorf("title template [%s] is not valid: %w", text, err)
+	}
+
+	c.TitleTemplate = text
+	c.title = parsed
+
+	return nil
+}
+
+// SetDuplicateTitles method sets what a sync does when pages would have the same title
+// (DuplicatesFail, DuplicatesSuffix or DuplicatesWarn) - empty sets DuplicatesFail
+func (c *Config) SetDuplicateTitles(mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+
+	switch mode {
+	case "":
+		mode = DuplicatesFail
+	case DuplicatesFail, DuplicatesSuffix, DuplicatesWarn:
+	default:
+		return fmt.Errorf("duplicate titles [%s] should be %s, %s or %s", mode, DuplicatesFail, DuplicatesSuffix, DuplicatesWarn)
+	}
+
+	c.DuplicateTitles = mode
+
+	return nil
+}
+
+// Title method returns the title of a page from the title template
+func (c *Config) Title(fields TitleFields) (string, error) {
+	if c.title == nil {
+		err := c.SetTitleTemplate(c.TitleTemplate)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	title, err := execute(c.title, fields)
+	if err != nil {
+		return "", fmt.Errorf("title template error for [%s]: %w", fields.Path, err)
+	}
+
+	return title, nil
+}
+
+// execute function returns the title the template makes from the fields - an error if it is empty
+func execute(title *template.Template, fields TitleFields) (string, error) {
+	var buf bytes.Buffer
+
+	err := title.Execute(&buf, fields)
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.Join(strings.Fields(buf.String()), " ")
+	if result == "" {
+		return "", fmt.Errorf("the title is empty")
+	}
+
+	return result, nil
+}
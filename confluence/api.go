@@ -1,21 +1,37 @@
 package confluence
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/xiatechs/markdown-to-confluence/common"
 )
 
+const defaultPageSize = 25 // confluence's own default when no limit is provided
+
+// removal modes for DeletePage
+const (
+	RemoveTrash   = "trash"   // move pages to the space trash
+	RemovePurge   = "purge"   // move pages to the trash and then purge them from it
+	RemoveArchive = "archive" // archive pages (or move them under an archive page)
+)
+
 //go:generate mockgen --source=api.go -package confluencemocks -destination=test/confluencemocks/api.go
 
 // APIClient struct for interacting with confluence
 type APIClient struct {
-	BaseURL string
-	Space   string
-	ApiKey  string
-	Client  HTTPClient
+	BaseURL  string
+	Space    string
+	ApiKey   string
+	Auth     Authenticator
+	Client   HTTPClient
+	PageSize int // number of results requested per page when listing/searching
+
+	RemovalMode   string // how DeletePage removes pages - RemoveTrash, RemovePurge or RemoveArchive
+	ArchivePageID int    // with RemoveArchive pages are moved under this page (if 0 the archive api is used)
 }
 
 // HTTPClient interface will allow mock Do request
@@ -25,21 +41,82 @@ type HTTPClient interface {
 
 // CreateAPIClient creates the API client with relevant login details for confluence's API
 func CreateAPIClient() (*APIClient, error) {
-	apiClient := APIClientWithAuths(retryablehttp.NewClient())
+	httpClient := retryablehttp.NewClient()
+	httpClient.CheckRetry = checkRetry(retryablehttp.DefaultRetryPolicy)
+	httpClient.HTTPClient.Timeout = common.RequestTimeout
+
+	apiClient := APIClientWithAuths(NewRateLimitedClient(httpClient,
+		common.ConfluenceRequestsPerSecond, common.ConfluenceRequestBurst))
 	if apiClient.ApiKey == "" ||
 		apiClient.Space == "" {
 		return nil, fmt.Errorf("%s", "one or more arguments are not set - please ensure they are before running this action")
 	}
 
+	auth, err := NewAuthenticator(common.ConfluenceAuthMethod, common.ConfluenceBaseURL, common.ConfluenceUsername,
+		common.ConfluenceAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("create api client error: %w", err)
+	}
+
+	apiClient.Auth = auth
+
 	return apiClient, nil
 }
 
 // APIClientWithAuths returns an APIClient with dependencies defaulted to sane values
+// if the auth settings are not valid the error is logged and requests fall back to bearer auth with the api key
 func APIClientWithAuths(httpClient HTTPClient) *APIClient {
-	return &APIClient{
-		BaseURL: common.ConfluenceBaseURL,
-		Space:   common.ConfluenceSpace,
-		ApiKey:  common.ConfluenceAPIKey,
-		Client:  httpClient,
+	apiClient := &APIClient{
+		BaseURL:  common.ConfluenceBaseURL,
+		Space:    common.ConfluenceSpace,
+		ApiKey:   common.ConfluenceAPIKey,
+		Client:   httpClient,
+		PageSize: common.ConfluencePageSize,
+
+		RemovalMode:   common.RemovalMode,
+		ArchivePageID: common.ArchivePageID,
+	}
+
+	auth, err := NewAuthenticator(common.ConfluenceAuthMethod, common.ConfluenceBaseURL, common.ConfluenceUsername,
+		common.ConfluenceAPIKey)
+	if err != nil {
+		log.Println(fmt.Errorf("api client auth error: %w", err))
+	}
+
+	apiClient.Auth = auth
+
+	return apiClient
+}
+
+// newRequest method creates a request for the confluence API with the client credentials applied
+// the request is abandoned if ctx is cancelled or its deadline passes
+func (a *APIClient) newRequest(ctx context.Context, method, url string, body interface{}) (*retryablehttp.Request, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
 	}
+
+	a.authenticate(req)
+
+	return req, nil
+}
+
+// pageSize method returns the number of results to request per page
+func (a *APIClient) pageSize() int {
+	if a.PageSize <= 0 {
+		return defaultPageSize
+	}
+
+	return a.PageSize
+}
+
+// authenticate method applies the client credentials to a request
+// falling back to a bearer token if no Authenticator has been set
+func (a *APIClient) authenticate(req *retryablehttp.Request) {
+	if a.Auth == nil {
+		BearerAuth{Token: a.ApiKey}.Authenticate(req)
+		return
+	}
+
+	a.Auth.Authenticate(req)
 }
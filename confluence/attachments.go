@@ -0,0 +1,288 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	attachmentComment = "file uploaded using markdown-github-action"
+	hashPrefix        = "sha256:" // the attachment comment holds the sha256 of the uploaded file after this prefix
+)
+
+// AttachmentResults contains the returned attachment values
+type AttachmentResults struct {
+	Results []Attachment `json:"results"`
+	Pagination
+}
+
+// Attachment holds a confluence attachment
+type Attachment struct {
+	ID         string               `json:"id"`
+	Title      string               `json:"title"`
+	Metadata   AttachmentMetadata   `json:"metadata"`
+	Extensions AttachmentExtensions `json:"extensions"`
+}
+
+// AttachmentMetadata contains the attachment comment
+type AttachmentMetadata struct {
+	Comment   string `json:"comment"`
+	MediaType string `json:"mediaType"`
+}
+
+// AttachmentExtensions contains the attachment file size (and comment on some versions of confluence)
+type AttachmentExtensions struct {
+	FileSize int64  `json:"fileSize"`
+	Comment  string `json:"comment"`
+}
+
+// Hash method returns the sha256 recorded in the attachment comment when it was uploaded
+// by the tool, or an empty string if the attachment was not uploaded by the tool
+func (a Attachment) Hash() string {
+	for _, comment := range []string{a.Metadata.Comment, a.Extensions.Comment} {
+		index := strings.Index(comment, hashPrefix)
+		if index == -1 {
+			continue
+		}
+
+		fields := strings.Fields(comment[index+len(hashPrefix):])
+		if len(fields) > 0 {
+			return fields[0]
+		}
+	}
+
+	return ""
+}
+
+// fileDigest function returns the sha256 and size of a file
+func fileDigest(path string) (string, int64, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("file close error: %w", err))
+		}
+	}()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// ListAttachments method returns every attachment on a page
+func (a *APIClient) ListAttachments(ctx context.Context, pageID int) ([]Attachment, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/child/attachment?expand=version,metadata,extensions&limit=%d",
+		a.BaseURL, pageID, a.pageSize())
+
+	return a.listAttachments(ctx, URL)
+}
+
+// findAttachment method returns the attachment on a page with the file name provided
+// or nil if the page has no such attachment
+func (a *APIClient) findAttachment(ctx context.Context, pageID int, fileName string) (*Attachment, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/child/attachment?filename=%s&expand=version,metadata,extensions",
+		a.BaseURL, pageID, url.QueryEscape(fileName))
+
+	attachments, err := a.listAttachments(ctx, URL)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range attachments {
+		if attachments[index].Title == fileName {
+			return &attachments[index], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listAttachments method collects every page of attachment results from URL
+func (a *APIClient) listAttachments(ctx context.Context, URL string) ([]Attachment, error) {
+	var attachments []Attachment
+
+	err := a.getAll(ctx, URL, func(contents []byte) (Pagination, error) {
+		results := AttachmentResults{}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return Pagination{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		attachments = append(attachments, results.Results...)
+
+		return results.Pagination, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list attachments error: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func newfileUploadRequest(ctx context.Context, uri string, paramName, path, comment string) (*retryablehttp.Request, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("file close error: %w", err))
+		}
+	}()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile(paramName, filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("create form file error: %w", err)
+	}
+
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return nil, fmt.Errorf("io copy error: %w", err)
+	}
+
+	params := map[string]string{
+		"comment":   comment,
+		"minorEdit": "true",
+	}
+
+	for key, val := range params {
+		_ = writer.WriteField(key, val)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, fmt.Errorf("writer close error: %w", err)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, uri, body.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+
+	req.Header = http.Header{
+		"Content-Type":      []string{writer.FormDataContentType()},
+		"X-Atlassian-Token": []string{"no-check"},
+	}
+
+	return req, nil
+}
+
+// UploadAttachment to a page identify by page ID
+// you need the page ID to upload the attachment(file path)
+// if the page already has an identical attachment (same sha256) nothing is uploaded
+// and if it has an attachment with the same name but different contents a new version is uploaded
+// it returns true if the attachment was uploaded
+func (a *APIClient) UploadAttachment(ctx context.Context, filename string, id int, isindex bool, indexid int) (bool, error) {
+	pageID := id
+
+	if isindex {
+		pageID = indexid
+	}
+
+	hash, size, err := fileDigest(filename)
+	if err != nil {
+		return false, fmt.Errorf("file upload error: %w", err)
+	}
+
+	existing, err := a.findAttachment(ctx, pageID, filepath.Base(filename))
+	if err != nil {
+		return false, fmt.Errorf("file upload error: %w", err)
+	}
+
+	targetURL := fmt.Sprintf("%s/rest/api/content/%d/child/attachment", a.BaseURL, pageID)
+
+	if existing != nil {
+		if existing.Hash() == hash {
+			log.Printf("attachment [%s] on page [%d] is unchanged - skipping upload", filepath.Base(filename), pageID)
+			return false, nil
+		}
+
+		log.Printf("attachment [%s] on page [%d] has changed (%d bytes) - uploading a new version",
+			filepath.Base(filename), pageID, size)
+
+		targetURL = fmt.Sprintf("%s/rest/api/content/%d/child/attachment/%s/data", a.BaseURL, pageID, existing.ID)
+	}
+
+	req, err := newfileUploadRequest(ctx, targetURL, "file", filename, attachmentComment+" "+hashPrefix+hash)
+	if err != nil {
+		return false, fmt.Errorf("file upload error: %w", err)
+	}
+
+	a.authenticate(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("upload attachment response error: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("upload attachment response issue: %w", newAPIError(resp))
+	}
+
+	return true, nil
+}
+
+// DeleteAttachment method deletes an attachment by attachment ID
+func (a *APIClient) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	URL := fmt.Sprintf("%s/rest/api/content/%s", a.BaseURL, attachmentID)
+
+	req, err := a.newRequest(ctx, http.MethodDelete, URL, nil)
+	if err != nil {
+		return fmt.Errorf("deleteattachment error: %w", err)
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("deleteattachment error: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("deleteattachment error for attachment [%s]: %w", attachmentID, newAPIError(resp))
+	}
+
+	return nil
+}
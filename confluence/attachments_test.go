@@ -0,0 +1,90 @@
+package confluence
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencemocks"
+)
+
+func TestAPIClient_UploadAttachmentExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "picture.png")
+
+	err := os.WriteFile(path, []byte("picture"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, _, err := fileDigest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := func(comment string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(fmt.Sprintf(
+				`{"results":[{"id":"att9","title":"picture.png","metadata":{"comment":%q}}]}`, comment))),
+		}
+	}
+
+	inputs := []struct {
+		name     string
+		setup    func(m *confluencemocks.MockHTTPClient)
+		uploaded bool
+	}{
+		{
+			name: "identical attachment is not uploaded again",
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				m.EXPECT().Do(gomock.Any()).Return(existing(attachmentComment+" "+hashPrefix+hash), nil)
+			},
+		},
+		{
+			name: "changed attachment is uploaded as a new version",
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				gomock.InOrder(
+					m.EXPECT().Do(gomock.Any()).Return(existing(attachmentComment+" "+hashPrefix+"stale"), nil),
+					m.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+						if !strings.HasSuffix(req.URL.Path, "/child/attachment/att9/data") {
+							return nil, fmt.Errorf("unexpected upload url %s", req.URL.Path)
+						}
+
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					}),
+				)
+			},
+			uploaded: true,
+		},
+	}
+
+	for _, test := range inputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+			test.setup(mock)
+
+			client := APIClientWithAuths(mock)
+
+			uploaded, err := client.UploadAttachment(context.Background(), path, 5, false, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, test.uploaded, uploaded)
+		})
+	}
+}
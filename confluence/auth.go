@@ -0,0 +1,93 @@
+package confluence
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// supported authentication methods for the confluence API
+const (
+	AuthBearer = "bearer" // data center personal access token
+	AuthBasic  = "basic"  // confluence cloud email + api token
+	AuthOAuth2 = "oauth2" // oauth2 (3LO) access token
+)
+
+// Authenticator applies credentials to a request before it is sent to confluence
+type Authenticator interface {
+	Authenticate(req *retryablehttp.Request)
+}
+
+// BearerAuth authenticates with a data center personal access token
+type BearerAuth struct {
+	Token string
+}
+
+// Authenticate sets the bearer token on the request
+func (b BearerAuth) Authenticate(req *retryablehttp.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.Token))
+}
+
+// BasicAuth authenticates with a confluence cloud account email and api token
+type BasicAuth struct {
+	Username string
+	Token    string
+}
+
+// Authenticate sets the basic auth credentials on the request
+func (b BasicAuth) Authenticate(req *retryablehttp.Request) {
+	req.SetBasicAuth(b.Username, b.Token)
+}
+
+// OAuth2Auth authenticates with an oauth2 access token obtained outside of the tool
+type OAuth2Auth struct {
+	AccessToken string
+}
+
+// Authenticate sets the oauth2 access token on the request
+func (o OAuth2Auth) Authenticate(req *retryablehttp.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.AccessToken))
+}
+
+// NewAuthenticator function returns the Authenticator for the auth method provided
+// if no method is provided then bearer auth is used - unless a username is set and baseURL is a confluence cloud
+// site (*.atlassian.net, which has no personal access tokens) when basic auth is used
+func NewAuthenticator(method, baseURL, username, key string) (Authenticator, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+
+	if method == "" {
+		method = AuthBearer
+
+		if username != "" && isCloud(baseURL) {
+			method = AuthBasic
+		}
+	}
+
+	switch method {
+	case AuthBearer:
+		return BearerAuth{Token: key}, nil
+	case AuthBasic:
+		if username == "" {
+			return nil, fmt.Errorf("basic auth requires a username (the confluence account email)")
+		}
+
+		return BasicAuth{Username: username, Token: key}, nil
+	case AuthOAuth2:
+		return OAuth2Auth{AccessToken: key}, nil
+	}
+
+	return nil, fmt.Errorf("unknown auth method [%s] - should be one of %s, %s or %s",
+		method, AuthBearer, AuthBasic, AuthOAuth2)
+}
+
+// isCloud function returns true if the base url is a confluence cloud site
+func isCloud(baseURL string) bool {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(parsed.Hostname()), ".atlassian.net")
+}
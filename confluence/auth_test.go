@@ -0,0 +1,119 @@
+package confluence
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/common"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	inputs := []struct {
+		name           string
+		method         string
+		baseURL        string
+		username       string
+		key            string
+		expectedHeader string
+		expectedError  bool
+	}{
+		{
+			name:           "defaults to bearer when no username is set",
+			key:            "token",
+			expectedHeader: "Bearer token",
+		},
+		{
+			name:           "defaults to bearer when a username is set for a data center site",
+			baseURL:        "https://confluence.example.com",
+			username:       "user@example.com",
+			key:            "token",
+			expectedHeader: "Bearer token",
+		},
+		{
+			name:           "defaults to basic when a username is set for a cloud site",
+			baseURL:        "https://example.atlassian.net/wiki",
+			username:       "user@example.com",
+			key:            "token",
+			expectedHeader: "Basic dXNlckBleGFtcGxlLmNvbTp0b2tlbg==",
+		},
+		{
+			name:           "basic when set for a data center site",
+			method:         AuthBasic,
+			baseURL:        "https://confluence.example.com",
+			username:       "user@example.com",
+			key:            "token",
+			expectedHeader: "Basic dXNlckBleGFtcGxlLmNvbTp0b2tlbg==",
+		},
+		{
+			name:           "oauth2 access token",
+			method:         "OAuth2",
+			key:            "access",
+			expectedHeader: "Bearer access",
+		},
+		{
+			name:          "basic without a username",
+			method:        AuthBasic,
+			key:           "token",
+			expectedError: true,
+		},
+		{
+			name:          "unknown method",
+			method:        "kerberos",
+			key:           "token",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range inputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			asserts := assert.New(t)
+
+			auth, err := NewAuthenticator(test.method, test.baseURL, test.username, test.key)
+			if test.expectedError {
+				asserts.Error(err)
+				return
+			}
+
+			asserts.NoError(err)
+
+			req, err := retryablehttp.NewRequest(http.MethodGet, "https://example.com", nil)
+			asserts.NoError(err)
+
+			auth.Authenticate(req)
+
+			asserts.Equal(test.expectedHeader, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+// TestAPIClientWithAuths_InvalidAuth checks an invalid auth method is logged rather than dropped silently
+// (and requests fall back to bearer auth with the api key)
+func TestAPIClientWithAuths_InvalidAuth(t *testing.T) {
+	method, key := common.ConfluenceAuthMethod, common.ConfluenceAPIKey
+	defer func() { common.ConfluenceAuthMethod, common.ConfluenceAPIKey = method, key }()
+
+	common.ConfluenceAuthMethod, common.ConfluenceAPIKey = "kerberos", "token"
+
+	var logged bytes.Buffer
+
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	client := APIClientWithAuths(nil)
+	assert.Nil(t, client.Auth)
+	assert.Contains(t, logged.String(), "unknown auth method [kerberos]")
+
+	req, err := retryablehttp.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.authenticate(req)
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
@@ -4,19 +4,16 @@ package confluence
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/xiatechs/markdown-to-confluence/common"
 	"github.com/xiatechs/markdown-to-confluence/markdown"
 )
 
@@ -77,7 +74,7 @@ func (a *APIClient) grabPageContents(contents *markdown.FileContents, root int,
 // CreatePage method takes root (root page id) and page contents and bool (is page root?)
 // and generates a page in confluence and returns the generated page ID
 // nolint: gocyclo // 11 is just about fine
-func (a *APIClient) CreatePage(root int, contents *markdown.FileContents, isroot bool) (int, error) {
+func (a *APIClient) CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error) {
 	if contents == nil {
 		return 0, fmt.Errorf("createpage error: contents parameter is nil")
 	}
@@ -98,13 +95,11 @@ func (a *APIClient) CreatePage(root int, contents *markdown.FileContents, isroot
 
 	URL := fmt.Sprintf("%s/rest/api/content", a.BaseURL)
 
-	req, err := retryablehttp.NewRequest(http.MethodPost, URL, newPageContentsJSON)
+	req, err := a.newRequest(ctx, http.MethodPost, URL, newPageContentsJSON)
 	if err != nil {
 		return 0, fmt.Errorf("createpage error: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -121,11 +116,7 @@ func (a *APIClient) CreatePage(root int, contents *markdown.FileContents, isroot
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		bytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return 0, fmt.Errorf("failed to create confluence page: status=%d, error=%w", resp.StatusCode, err)
-		}
-		return 0, fmt.Errorf("failed to create confluence page: status=%d, response=%s", resp.StatusCode, string(bytes))
+		return 0, fmt.Errorf("failed to create confluence page: %w", newAPIError(resp))
 	}
 
 	decoder := json.NewDecoder(resp.Body)
@@ -148,7 +139,9 @@ func (a *APIClient) CreatePage(root int, contents *markdown.FileContents, isroot
 }
 
 // updatePageContents method updates the page contents and return as a []byte JSON to be used
-func (a *APIClient) updatePageContents(pageVersion int64, contents *markdown.FileContents) ([]byte, *Page, error) {
+// if parentID is not 0 the page is moved under the parent page
+func (a *APIClient) updatePageContents(pageVersion int64, contents *markdown.FileContents,
+	parentID int) ([]byte, *Page, error) {
 	newPageContent := Page{
 		Type:  "page",
 		Title: contents.MetaData["title"].(string),
@@ -164,6 +157,10 @@ func (a *APIClient) updatePageContents(pageVersion int64, contents *markdown.Fil
 		},
 	}
 
+	if parentID != 0 {
+		newPageContent.Ancestors = []AncestorObj{{ID: parentID}}
+	}
+
 	newPageContentsJSON, err := json.Marshal(newPageContent)
 	if err != nil {
 		return nil, nil, fmt.Errorf("json marshal error: %w", err)
@@ -172,17 +169,52 @@ func (a *APIClient) updatePageContents(pageVersion int64, contents *markdown.Fil
 	return newPageContentsJSON, &newPageContent, nil
 }
 
-// DeletePage deletes a confluence page by page ID
-func (a *APIClient) DeletePage(pageID int) error {
+// DeletePage removes a confluence page by page ID using the client's removal mode:
+// trash (default) moves the page to the space trash, purge also removes it from the trash
+// and archive moves the page under the archive page (or archives it if no archive page is set)
+func (a *APIClient) DeletePage(ctx context.Context, pageID int) error {
+	switch a.RemovalMode {
+	case "", RemoveTrash:
+		return a.trashPage(ctx, pageID)
+	case RemovePurge:
+		err := a.trashPage(ctx, pageID)
+		if err != nil {
+			return err
+		}
+
+		return a.purgePage(ctx, pageID)
+	case RemoveArchive:
+		if a.ArchivePageID != 0 {
+			return a.MovePage(ctx, pageID, a.ArchivePageID)
+		}
+
+		return a.archivePage(ctx, pageID)
+	}
+
+	return fmt.Errorf("deletepage error: unknown removal mode [%s]", a.RemovalMode)
+}
+
+// trashPage method moves a page to the space trash
+func (a *APIClient) trashPage(ctx context.Context, pageID int) error {
 	URL := fmt.Sprintf("%s/rest/api/content/%d", a.BaseURL, pageID)
 
-	req, err := retryablehttp.NewRequest(http.MethodDelete, URL, nil)
+	return a.doDelete(ctx, URL)
+}
+
+// purgePage method removes a page that is already in the trash permanently
+func (a *APIClient) purgePage(ctx context.Context, pageID int) error {
+	URL := fmt.Sprintf("%s/rest/api/content/%d?status=trashed", a.BaseURL, pageID)
+
+	return a.doDelete(ctx, URL)
+}
+
+// doDelete method sends a delete request and returns an APIError if it was not successful
+func (a *APIClient) doDelete(ctx context.Context, URL string) error {
+	req, err := a.newRequest(ctx, http.MethodDelete, URL, nil)
 	if err != nil {
 		return fmt.Errorf("deletepage error: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := a.Client.Do(req)
@@ -197,50 +229,194 @@ func (a *APIClient) DeletePage(pageID int) error {
 		}
 	}()
 
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("deletepage error: %w", newAPIError(resp))
+	}
+
+	return nil
+}
+
+// archivePage method archives a page using the confluence cloud archive api
+func (a *APIClient) archivePage(ctx context.Context, pageID int) error {
+	URL := fmt.Sprintf("%s/rest/api/content/archive", a.BaseURL)
+
+	body := fmt.Sprintf(`{"pages":[{"id":%d}]}`, pageID)
+
+	req, err := a.newRequest(ctx, http.MethodPost, URL, []byte(body))
+	if err != nil {
+		return fmt.Errorf("archivepage error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Client.Do(req)
 	if err != nil {
-		log.Println("deletepage error", resp.Status, err)
+		return fmt.Errorf("archivepage error: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("archivepage error: %w", newAPIError(resp))
 	}
 
 	return nil
 }
 
+// MovePage method moves a page (and its children) under a new parent page
+// by updating the page ancestor - keeping the page history, comments and links
+func (a *APIClient) MovePage(ctx context.Context, pageID, parentID int) error {
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		current, err := a.GetPage(ctx, pageID)
+		if err != nil {
+			return fmt.Errorf("movepage error: %w", err)
+		}
+
+		moved := Page{
+			Type:      "page",
+			Title:     current.Title,
+			Space:     SpaceObj{Key: a.Space},
+			Version:   VersionObj{Number: current.Version.Number + 1},
+			Ancestors: []AncestorObj{{ID: parentID}},
+			Body: BodyObj{Storage: StorageObj{
+				Value:          current.Body.Storage.Value,
+				Representation: "storage",
+			}},
+		}
+
+		movedJSON, err := json.Marshal(moved)
+		if err != nil {
+			return fmt.Errorf("movepage json marshal error: %w", err)
+		}
+
+		conflict, err := a.putPage(ctx, pageID, movedJSON)
+		if err != nil {
+			return fmt.Errorf("movepage error: %w", err)
+		}
+
+		if !conflict {
+			log.Printf("moved page [%d] under page [%d]", pageID, parentID)
+			return nil
+		}
+	}
+
+	return &ConflictError{PageID: pageID, Attempts: maxConflictRetries + 1}
+}
+
 // UpdatePage updates a confluence page with our newly created data and increases the
 // version by 1 each time.
-func (a *APIClient) UpdatePage(pageID int, pageVersion int64, pageContents *markdown.FileContents,
-	originalPage PageResults) (bool, error) {
-	newPageContentsJSON, newPage, err := a.updatePageContents(pageVersion, pageContents)
-	if err != nil {
-		return false, fmt.Errorf("updatePageContents error: %w", err)
-	}
+// if parentID is not 0 and the page is not under it (the file moved to a different folder)
+// the page is moved under parentID as part of the update - keeping its history, comments and links
+// if the page was edited since it was found (409 conflict) the latest version is fetched,
+// and if the page still needs changing the update is retried up to maxConflictRetries times
+// it returns the version the page was updated to - or 0 if the page was unchanged so it was not written
+func (a *APIClient) UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents,
+	originalPage PageResults) (int, error) {
+	var current *Page
 
 	if len(originalPage.Results) > 0 {
-		if originalPage.Results[0].Body.Storage == newPage.Body.Storage {
+		current = &originalPage.Results[0]
+	}
+
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		move := current != nil && parentID != 0 && current.ParentID() != parentID
+
+		newParentID := 0
+		if move {
+			newParentID = parentID
+		}
+
+		newPageContentsJSON, newPage, err := a.updatePageContents(pageVersion, pageContents, newParentID)
+		if err != nil {
+			return 0, fmt.Errorf("updatePageContents error: %w", err)
+		}
+
+		if current != nil && !move && PageUnchanged(*current, *newPage) {
 			log.Println("No changes to this page")
-			return true, nil
+			return 0, nil
+		}
+
+		if move {
+			log.Printf("moving page [%d] from page [%d] to page [%d]", pageID, current.ParentID(), parentID)
 		}
+
+		conflict, err := a.putPage(ctx, pageID, newPageContentsJSON)
+		if err != nil {
+			return 0, err
+		}
+
+		if !conflict {
+			return int(pageVersion) + 1, nil
+		}
+
+		log.Printf("page [%d] was changed since version [%d] was read - fetching the latest version", pageID, pageVersion)
+
+		current, err = a.GetPage(ctx, pageID)
+		if err != nil {
+			return 0, fmt.Errorf("updatepage failed to fetch page after version conflict: %w", err)
+		}
+
+		pageVersion = int64(current.Version.Number)
 	}
 
+	return 0, &ConflictError{PageID: pageID, Attempts: maxConflictRetries + 1}
+}
+
+// putPage method sends the updated page contents to confluence
+// and returns true if confluence rejected the update due to a version conflict
+func (a *APIClient) putPage(ctx context.Context, pageID int, newPageContentsJSON []byte) (bool, error) {
 	URL := fmt.Sprintf("%s/rest/api/content/%d", a.BaseURL, pageID)
 
-	req, err := retryablehttp.NewRequest(http.MethodPut, URL, bytes.NewBuffer(newPageContentsJSON))
+	req, err := a.newRequest(ctx, http.MethodPut, URL, bytes.NewBuffer(newPageContentsJSON))
 	if err != nil {
 		return false, fmt.Errorf("updatePageContents error: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := a.Client.Do(req)
 	if err != nil {
 		return false, fmt.Errorf("updatepage failed to do the request: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		bytes, err := io.ReadAll(resp.Body)
+
+	defer func() {
+		err := resp.Body.Close()
 		if err != nil {
-			return false, fmt.Errorf("updatepage failed to do the request and subsequently failed to read the response body: status=%d, error=%w", resp.StatusCode, err)
+			log.Println(fmt.Errorf("body close error: %w", err))
 		}
-		return false, fmt.Errorf("updatepage failed to do the request: status=%d, response=%s", resp.StatusCode, string(bytes))
+	}()
+
+	if resp.StatusCode == http.StatusConflict {
+		return true, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("updatepage failed to do the request: %w", newAPIError(resp))
+	}
+
+	return false, nil
+}
+
+// GetPage method returns the current version and body of a page by page ID
+func (a *APIClient) GetPage(ctx context.Context, pageID int) (*Page, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d?expand=body.storage,version,ancestors", a.BaseURL, pageID)
+
+	req, err := a.newRequest(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getpage error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getpage failed to do the request: %w", err)
 	}
 
 	defer func() {
@@ -250,54 +426,68 @@ func (a *APIClient) UpdatePage(pageID int, pageVersion int64, pageContents *mark
 		}
 	}()
 
-	return true, nil
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("getpage read error: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getpage failed to do the request: %w", newAPIErrorFromContents(resp, contents))
+	}
+
+	page := Page{}
+
+	err = json.Unmarshal(contents, &page)
+	if err != nil {
+		return nil, fmt.Errorf("getpage json unmarshal error: %w", err)
+	}
+
+	return &page, nil
 }
 
 // createFindPageRequest method takes in a title (page title) and searches for page
 // in confluence
-func (a *APIClient) createFindPageRequest(title string) (*retryablehttp.Request, error) {
-	lookUpURL := fmt.Sprintf("%s/rest/api/content?expand=body.storage,version&type=page&spaceKey=%s&title=%s",
-		a.BaseURL, a.Space, title)
+func (a *APIClient) createFindPageRequest(ctx context.Context, title string) (*retryablehttp.Request, error) {
+	lookUpURL := fmt.Sprintf("%s/rest/api/content?expand=body.storage,version,ancestors,metadata.properties.%s"+
+		"&type=page&spaceKey=%s&title=%s&limit=%d",
+		a.BaseURL, ManagedPropertyKey, a.Space, url.QueryEscape(title), a.pageSize())
 
-	req, err := retryablehttp.NewRequest(http.MethodGet, lookUpURL, nil)
+	req, err := a.newRequest(ctx, http.MethodGet, lookUpURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("createFindPageRequest error: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-
 	return req, nil
 }
 
 // createFindPagesRequest method takes in a page ID and searches for page
 // in confluence as well as children pages
-func (a *APIClient) createFindPagesRequest(id string) (*retryablehttp.Request, error) {
-	targetURL := fmt.Sprintf(common.ConfluenceBaseURL + "/rest/api/content/" + id + "/child/page")
+func (a *APIClient) createFindPagesRequest(ctx context.Context, id string) (*retryablehttp.Request, error) {
+	targetURL := fmt.Sprintf("%s/rest/api/content/%s/child/page?expand=version,ancestors,metadata.properties.%s&limit=%d",
+		a.BaseURL, id, ManagedPropertyKey, a.pageSize())
 
-	req, err := retryablehttp.NewRequest(http.MethodGet, targetURL, nil)
+	req, err := a.newRequest(ctx, http.MethodGet, targetURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("createFindPagesRequest error: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-
 	return req, nil
 }
 
 // findPageRequest method takes in page title (and bool for if we are collecting multiple pages i.e
 // parent and child pages
-func (a *APIClient) findPageRequest(title string, many bool) (*retryablehttp.Request, error) {
+func (a *APIClient) findPageRequest(ctx context.Context, title string, many bool) (*retryablehttp.Request, error) {
 	var req *retryablehttp.Request
 
 	var err error
 
 	if many {
-		req, err = a.createFindPagesRequest(title)
+		req, err = a.createFindPagesRequest(ctx, title)
 		if err != nil {
 			return nil, fmt.Errorf("createFindPagesRequest error: %w", err)
 		}
 	} else {
-		req, err = a.createFindPageRequest(title)
+		req, err = a.createFindPageRequest(ctx, title)
 		if err != nil {
 			return nil, fmt.Errorf("createFindPageRequest error: %w", err)
 		}
@@ -306,15 +496,42 @@ func (a *APIClient) findPageRequest(title string, many bool) (*retryablehttp.Req
 	return req, err
 }
 
-// FindPage in confluence
-// Docs for this API endpoint are here
-// https://developer.atlassian.com/cloud/confluence/rest/api-group-content/#api-api-content-get
-func (a *APIClient) FindPage(title string, many bool) (*PageResults, error) {
-	req, err := a.findPageRequest(title, many)
-	if err != nil {
-		return nil, fmt.Errorf("find page request error: %w", err)
+// nextPageRequest method takes in the request for the current page of results and the
+// pagination values it returned, and returns the request for the next page of results
+// or nil if the results are exhausted
+func (a *APIClient) nextPageRequest(current *retryablehttp.Request,
+	pagination Pagination) (*retryablehttp.Request, error) {
+	var nextURL string
+
+	switch {
+	case pagination.Links.Next != "":
+		base := pagination.Links.Base
+		if base == "" {
+			base = a.BaseURL
+		}
+
+		nextURL = base + pagination.Links.Next
+	case pagination.Limit > 0 && pagination.Size >= pagination.Limit: // no links returned so fall back to start+limit
+		query := current.URL.Query()
+		query.Set("start", strconv.Itoa(pagination.Start+pagination.Size))
+		query.Set("limit", strconv.Itoa(pagination.Limit))
+
+		next := *current.URL
+		next.RawQuery = query.Encode()
+		nextURL = next.String()
+	default:
+		return nil, nil
 	}
 
+	if nextURL == current.URL.String() {
+		return nil, fmt.Errorf("next page of results has the same url as the current page [%s]", nextURL)
+	}
+
+	return a.newRequest(current.Context(), http.MethodGet, nextURL, nil)
+}
+
+// getPageResults method does the request and returns a single page of results
+func (a *APIClient) getPageResults(req *retryablehttp.Request) (*PageResults, error) {
 	resp, err := a.Client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("find page request error: %w", err)
@@ -327,110 +544,105 @@ func (a *APIClient) FindPage(title string, many bool) (*PageResults, error) {
 		}
 	}()
 
-	results, err := newPageResults(resp)
-	if err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("find page request error: %w", newAPIError(resp))
 	}
 
-	return results, nil
+	return newPageResults(resp)
 }
 
-func newfileUploadRequest(uri string, paramName, path string) (*retryablehttp.Request, error) {
-	file, err := os.Open(filepath.Clean(path))
+// getAll method requests URL and every following page of results, passing the body of
+// each response to collect - which decodes the results and returns their pagination values
+func (a *APIClient) getAll(ctx context.Context, URL string, collect func(contents []byte) (Pagination, error)) error {
+	req, err := a.newRequest(ctx, http.MethodGet, URL, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	defer func() {
-		err := file.Close()
+	for req != nil {
+		req.Header.Set("Accept", "application/json")
+
+		contents, err := a.getContents(req)
 		if err != nil {
-			log.Println(fmt.Errorf("file close error: %w", err))
+			return err
 		}
-	}()
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	part, err := writer.CreateFormFile(paramName, filepath.Base(path))
-	if err != nil {
-		return nil, fmt.Errorf("create form file error: %w", err)
-	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return nil, fmt.Errorf("io copy error: %w", err)
-	}
+		pagination, err := collect(contents)
+		if err != nil {
+			return err
+		}
 
-	params := map[string]string{
-		"comment":   "file uploaded using markdown-github-action",
-		"minorEdit": "true",
+		req, err = a.nextPageRequest(req, pagination)
+		if err != nil {
+			return err
+		}
 	}
 
-	for key, val := range params {
-		_ = writer.WriteField(key, val)
-	}
+	return nil
+}
 
-	req, err := retryablehttp.NewRequest("PUT", uri, body)
+// getContents method does the request and returns the response body
+// returning an error if the response was not a 200
+func (a *APIClient) getContents(req *retryablehttp.Request) ([]byte, error) {
+	resp, err := a.Client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("writer close error: %w", err)
+		return nil, fmt.Errorf("failed to do the request: %w", err)
 	}
 
-	data := []byte{}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
 
-	_, err = file.Read(data)
+	contents, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println(err)
+		return nil, fmt.Errorf("read response error: %w", err)
 	}
 
-	sEnc := base64.StdEncoding.EncodeToString(data)
-	req.ContentLength = int64(len(sEnc))
-
-	req.Header = http.Header{
-		"Content-Type": []string{writer.FormDataContentType()},
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed: %w", newAPIErrorFromContents(resp, contents))
 	}
 
-	err = writer.Close()
+	return contents, nil
+}
+
+// FindPage in confluence
+// results are paginated so every page of results is collected before returning
+// Docs for this API endpoint are here
+// https://developer.atlassian.com/cloud/confluence/rest/api-group-content/#api-api-content-get
+func (a *APIClient) FindPage(ctx context.Context, title string, many bool) (*PageResults, error) {
+	req, err := a.findPageRequest(ctx, title, many)
 	if err != nil {
-		return nil, fmt.Errorf("writer close error: %w", err)
+		return nil, fmt.Errorf("find page request error: %w", err)
 	}
 
-	return req, err
-}
-
-// UploadAttachment to a page identify by page ID
-// you need the page ID to upload the attachment(file path)
-func (a *APIClient) UploadAttachment(filename string, id int, isindex bool, indexid int) error {
-	var targetURL string
+	collected := PageResults{}
 
-	if isindex {
-		targetURL = fmt.Sprintf(common.ConfluenceBaseURL+"/rest/api/content/%d/child/attachment", indexid)
-	} else {
-		targetURL = fmt.Sprintf(common.ConfluenceBaseURL+"/rest/api/content/%d/child/attachment", id)
-	}
+	for req != nil {
+		results, err := a.getPageResults(req)
+		if err != nil {
+			return nil, err
+		}
 
-	req, err := newfileUploadRequest(targetURL, "file", filename)
-	if err != nil {
-		return fmt.Errorf("file upload error: %w", err)
-	}
+		if results == nil {
+			break
+		}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.ApiKey))
-	req.Header.Set("Accept", "application/json")
+		collected.Results = append(collected.Results, results.Results...)
 
-	resp, err := a.Client.Do(req)
-	if err != nil {
-		return fmt.Errorf("upload attachment response error: %w", err)
+		req, err = a.nextPageRequest(req, results.Pagination)
+		if err != nil {
+			return nil, fmt.Errorf("find page request error: %w", err)
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("upload attachment response issue: %s", resp.Status)
+	if len(collected.Results) == 0 { // we want to return nil to skip this result
+		return nil, nil
 	}
 
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Println(fmt.Errorf("body close error: %w", err))
-		}
-	}()
+	collected.Size = len(collected.Results)
 
-	return nil
+	return &collected, nil
 }
@@ -1,6 +1,7 @@
 package confluence
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencemocks"
 	"github.com/xiatechs/markdown-to-confluence/markdown"
@@ -20,6 +22,7 @@ func TestAPIClient_UpdatePage(t *testing.T) {
 		name          string
 		pageVersion   int64
 		pageID        int
+		parentID      int
 		pageContent   *markdown.FileContents
 		setup         func(*confluencemocks.MockHTTPClient)
 		expectedError error
@@ -41,6 +44,101 @@ func TestAPIClient_UpdatePage(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name:        "page under a different parent is moved",
+			pageVersion: int64(1),
+			pageID:      321,
+			parentID:    99,
+			pageContent: &markdown.FileContents{
+				MetaData: map[string]interface{}{"title": "pageTitle"},
+				Body:     []byte("testing"),
+			},
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				m.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+					body, err := req.BodyBytes()
+					if err != nil {
+						return nil, err
+					}
+
+					if !strings.Contains(string(body), `"ancestors":[{"id":99}]`) {
+						return nil, fmt.Errorf("page was not moved: %s", body)
+					}
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil
+				})
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "version conflict, retries with the latest version",
+			pageVersion: int64(1),
+			pageID:      321,
+			pageContent: &markdown.FileContents{
+				MetaData: map[string]interface{}{"title": "pageTitle"},
+				Body:     []byte("some text"),
+			},
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				gomock.InOrder(
+					m.EXPECT().Do(gomock.Any()).Return(&http.Response{
+						StatusCode: http.StatusConflict,
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil),
+					m.EXPECT().Do(gomock.Any()).Return(&http.Response{
+						StatusCode: http.StatusOK,
+						Body: io.NopCloser(strings.NewReader(
+							`{"id":"321","version":{"number":5},"body":{"storage":{"value":"edited"}}}`)),
+					}, nil),
+					m.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+						body, err := req.BodyBytes()
+						if err != nil {
+							return nil, err
+						}
+
+						page := Page{}
+						_ = json.Unmarshal(body, &page)
+
+						if page.Version.Number != 6 {
+							return nil, fmt.Errorf("expected version 6 got %d", page.Version.Number)
+						}
+
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					}),
+				)
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "version conflict persists",
+			pageVersion: int64(1),
+			pageID:      321,
+			pageContent: &markdown.FileContents{
+				MetaData: map[string]interface{}{"title": "pageTitle"},
+				Body:     []byte("some text"),
+			},
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				m.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+					if req.Method == http.MethodPut {
+						return &http.Response{
+							StatusCode: http.StatusConflict,
+							Body:       io.NopCloser(strings.NewReader("")),
+						}, nil
+					}
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body: io.NopCloser(strings.NewReader(
+							`{"id":"321","version":{"number":5},"body":{"storage":{"value":"edited"}}}`)),
+					}, nil
+				}).Times(2 * (maxConflictRetries + 1))
+			},
+			expectedError: &ConflictError{PageID: 321, Attempts: maxConflictRetries + 1},
+		},
 	}
 
 	for _, test := range newPages {
@@ -64,7 +162,8 @@ func TestAPIClient_UpdatePage(t *testing.T) {
 			oldResults := PageResults{
 				Results: []Page{
 					{
-						ID: "test",
+						ID:        "test",
+						Ancestors: []AncestorObj{{ID: 12}},
 						Body: BodyObj{
 							Storage: StorageObj{
 								Value: "testing",
@@ -74,7 +173,7 @@ func TestAPIClient_UpdatePage(t *testing.T) {
 				},
 			}
 
-			_, err := apiClient.UpdatePage(test.pageID, test.pageVersion, test.pageContent, oldResults)
+			_, err := apiClient.UpdatePage(context.Background(), test.pageID, test.parentID, test.pageVersion, test.pageContent, oldResults)
 
 			asserts.Equal(err, test.expectedError)
 		})
@@ -154,13 +253,53 @@ func TestAPIClient_FindPage(t *testing.T) {
 			defer setEnvs(envs, false)
 
 			client := APIClientWithAuths(mock)
-			_, err := client.FindPage(test.PageTitle, false)
+			_, err := client.FindPage(context.Background(), test.PageTitle, false)
 
 			asserts.Equal(test.ExpectedErr, err)
 		})
 	}
 }
 
+func TestAPIClient_FindPagePaginates(t *testing.T) {
+	firstPage := `{"results":[{"id":"1","title":"one"},{"id":"2","title":"two"}],"start":0,"limit":2,"size":2,` +
+		`"_links":{"base":"https://example.com/wiki","next":"/rest/api/content/9/child/page?limit=2&start=2"}}`
+	lastPage := `{"results":[{"id":"3","title":"three"}],"start":2,"limit":2,"size":1,"_links":{}}`
+
+	asserts := assert.New(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	gomock.InOrder(
+		mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+			asserts.Equal("2", req.URL.Query().Get("limit"))
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(firstPage)),
+			}, nil
+		}),
+		mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+			asserts.Equal("https://example.com/wiki/rest/api/content/9/child/page?limit=2&start=2", req.URL.String())
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(lastPage)),
+			}, nil
+		}),
+	)
+
+	client := APIClientWithAuths(mock)
+	client.PageSize = 2
+
+	results, err := client.FindPage(context.Background(), "9", true)
+	asserts.NoError(err)
+	asserts.Len(results.Results, 3)
+	asserts.Equal("three", results.Results[2].Title)
+}
+
 func TestAPIClient_CreatePage(t *testing.T) {
 	returnedPage := Page{
 		ID:      "321",
@@ -212,7 +351,7 @@ func TestAPIClient_CreatePage(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader("")),
 				}, nil)
 			},
-			expectedError: fmt.Errorf("failed to create confluence page: %s", "Not Found"),
+			expectedError: ErrNotFound,
 		},
 	}
 
@@ -233,8 +372,13 @@ func TestAPIClient_CreatePage(t *testing.T) {
 			defer setEnvs(envs, false)
 
 			client := APIClientWithAuths(mock)
-			_, err := client.CreatePage(0, test.pageContent, true)
-			asserts.Equal(test.expectedError, err)
+			_, err := client.CreatePage(context.Background(), 0, test.pageContent, true)
+			if test.expectedError != nil {
+				asserts.ErrorIs(err, test.expectedError)
+				return
+			}
+
+			asserts.NoError(err)
 		})
 	}
 }
@@ -252,7 +396,101 @@ func TestAPIClient_UploadAttachment(t *testing.T) {
 	defer setEnvs(envs, false)
 
 	client := APIClientWithAuths(mock)
-	err := client.UploadAttachment("thisfiledoesnotexist", 0, false, 0)
+	_, err := client.UploadAttachment(context.Background(), "thisfiledoesnotexist", 0, false, 0)
 
 	asserts.Equal(err.Error(), "file upload error: open thisfiledoesnotexist: no such file or directory")
 }
+
+func TestAPIClient_DeletePage(t *testing.T) {
+	response := func(status int) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Body:       io.NopCloser(strings.NewReader("")),
+		}
+	}
+
+	expectDelete := func(m *confluencemocks.MockHTTPClient, query string, status int) *gomock.Call {
+		return m.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+			if req.Method != http.MethodDelete || req.URL.RawQuery != query {
+				return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+			}
+
+			return response(status), nil
+		})
+	}
+
+	inputs := []struct {
+		name        string
+		mode        string
+		setup       func(m *confluencemocks.MockHTTPClient)
+		expectedErr bool
+	}{
+		{
+			name: "trash",
+			mode: RemoveTrash,
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				expectDelete(m, "", http.StatusNoContent)
+			},
+		},
+		{
+			name: "trash failure is reported",
+			mode: RemoveTrash,
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				expectDelete(m, "", http.StatusForbidden)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "purge removes the page from the trash",
+			mode: RemovePurge,
+			setup: func(m *confluencemocks.MockHTTPClient) {
+				gomock.InOrder(
+					expectDelete(m, "", http.StatusNoContent),
+					expectDelete(m, "status=trashed", http.StatusNoContent),
+				)
+			},
+		},
+		{
+			name:        "unknown mode",
+			mode:        "shred",
+			setup:       func(m *confluencemocks.MockHTTPClient) {},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range inputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+			test.setup(mock)
+
+			client := APIClientWithAuths(mock)
+			client.RemovalMode = test.mode
+
+			err := client.DeletePage(context.Background(), 5)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPage_ParentID(t *testing.T) {
+	page := Page{}
+
+	err := json.Unmarshal([]byte(`{"id":"5","ancestors":[{"id":"1"},{"id":"12"}]}`), &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 12, page.ParentID())
+	assert.Equal(t, 0, Page{}.ParentID())
+}
@@ -0,0 +1,167 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxConflictRetries = 3 // number of times an update is retried after a version conflict
+
+// errors returned by the api client can be matched against these with errors.Is
+var (
+	ErrNotFound             = errors.New("not found")              // the page, attachment or parent does not exist
+	ErrUnauthorized         = errors.New("unauthorized")           // the credentials were rejected
+	ErrForbidden            = errors.New("forbidden")              // the credentials lack permission for the content
+	ErrConflict             = errors.New("version conflict")       // the page was changed by someone else
+	ErrTitleExists          = errors.New("title already exists")   // another page in the space has the same title
+	ErrRateLimited          = errors.New("rate limited")           // confluence kept throttling the request
+	ErrPayloadTooLarge      = errors.New("payload too large")      // the page body or attachment is too big
+	ErrInvalidStorageFormat = errors.New("invalid storage format") // confluence could not parse the page body
+)
+
+// ConflictError is returned when a page could not be updated because
+// its version kept changing while we were trying to update it
+type ConflictError struct {
+	PageID   int
+	Attempts int
+}
+
+// Error method returns the error message
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("page [%d] could not be updated after %d attempts: %s", e.PageID, e.Attempts, ErrConflict)
+}
+
+// Is method lets errors.Is match a ConflictError against ErrConflict
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// APIError is returned when confluence responds with an unexpected status code
+// it can be matched against the Err* sentinels above with errors.Is
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string // the message from confluence's error response (or the whole response if it was not json)
+}
+
+// Error method returns the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s failed: status=%d, response=%s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// Unwrap method returns the sentinel for the status code and message of the error (or nil)
+// so the error can be matched with errors.Is
+func (e *APIError) Unwrap() error {
+	return e.kind()
+}
+
+// kind method returns the sentinel for the status code and message of the error
+func (e *APIError) kind() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge
+	case http.StatusBadRequest:
+		message := strings.ToLower(e.Message)
+
+		switch {
+		case strings.Contains(message, "title already exists"),
+			strings.Contains(message, "already exists with the title"),
+			strings.Contains(message, "already exists with the same title"):
+			return ErrTitleExists
+		case strings.Contains(message, "parsing xhtml"),
+			strings.Contains(message, "invalid storage format"),
+			strings.Contains(message, "unable to parse"),
+			strings.Contains(message, "cannot be converted"):
+			return ErrInvalidStorageFormat
+		}
+	}
+
+	return nil
+}
+
+// errorResponse is the error json returned by confluence
+// (v1 returns a message, v2 a list of errors)
+type errorResponse struct {
+	Message string `json:"message"`
+	Errors  []struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// message method returns the messages in the error response joined together
+func (r errorResponse) message() string {
+	messages := []string{}
+
+	if r.Message != "" {
+		messages = append(messages, r.Message)
+	}
+
+	for index := range r.Errors {
+		for _, message := range []string{r.Errors[index].Title, r.Errors[index].Detail} {
+			if message != "" {
+				messages = append(messages, message)
+			}
+		}
+	}
+
+	return strings.Join(messages, " - ")
+}
+
+// newAPIError function returns an APIError for the response
+// using the message from confluence's error json as the message if there is one
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
+	}
+
+	if resp.Body != nil {
+		contents, err := io.ReadAll(resp.Body)
+		if err == nil {
+			apiErr.Message = parseErrorMessage(contents)
+		}
+	}
+
+	return apiErr
+}
+
+// newAPIErrorFromContents function returns an APIError for a response whose body has already been read
+func newAPIErrorFromContents(resp *http.Response, contents []byte) *APIError {
+	apiErr := newAPIError(&http.Response{StatusCode: resp.StatusCode, Request: resp.Request})
+	apiErr.Message = parseErrorMessage(contents)
+
+	return apiErr
+}
+
+// parseErrorMessage function returns the message from confluence's error json
+// or the whole response if it is not json
+func parseErrorMessage(contents []byte) string {
+	response := errorResponse{}
+
+	err := json.Unmarshal(contents, &response)
+	if err == nil {
+		if message := response.message(); message != "" {
+			return message
+		}
+	}
+
+	return strings.TrimSpace(string(contents))
+}
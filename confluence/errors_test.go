@@ -0,0 +1,100 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	inputs := []struct {
+		name            string
+		status          int
+		body            string
+		expected        error
+		expectedMessage string
+	}{
+		{
+			name:            "not found",
+			status:          http.StatusNotFound,
+			body:            `{"statusCode":404,"message":"No content found with id: 7"}`,
+			expected:        ErrNotFound,
+			expectedMessage: "No content found with id: 7",
+		},
+		{
+			name:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			body:     "Basic authentication with passwords is deprecated",
+			expected: ErrUnauthorized,
+		},
+		{
+			name:     "forbidden",
+			status:   http.StatusForbidden,
+			expected: ErrForbidden,
+		},
+		{
+			name:     "conflict",
+			status:   http.StatusConflict,
+			expected: ErrConflict,
+		},
+		{
+			name:     "rate limited",
+			status:   http.StatusTooManyRequests,
+			expected: ErrRateLimited,
+		},
+		{
+			name:     "payload too large",
+			status:   http.StatusRequestEntityTooLarge,
+			expected: ErrPayloadTooLarge,
+		},
+		{
+			name:   "title already exists",
+			status: http.StatusBadRequest,
+			body: `{"statusCode":400,"message":"com.atlassian.confluence.api.service.exceptions.BadRequestException: ` +
+				`A page with this title already exists: A page already exists with the title docs in this space"}`,
+			expected: ErrTitleExists,
+		},
+		{
+			name:            "invalid storage format (v2 errors)",
+			status:          http.StatusBadRequest,
+			body:            `{"errors":[{"status":400,"title":"Error parsing xhtml: Unexpected close tag </p>"}]}`,
+			expected:        ErrInvalidStorageFormat,
+			expectedMessage: "Error parsing xhtml: Unexpected close tag </p>",
+		},
+		{
+			name:   "other bad request",
+			status: http.StatusBadRequest,
+			body:   `{"message":"something else"}`,
+		},
+	}
+
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrConflict, ErrTitleExists,
+		ErrRateLimited, ErrPayloadTooLarge, ErrInvalidStorageFormat}
+
+	for _, test := range inputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", newAPIError(&http.Response{
+				StatusCode: test.status,
+				Body:       io.NopCloser(strings.NewReader(test.body)),
+			}))
+
+			for _, sentinel := range sentinels {
+				assert.Equal(t, sentinel == test.expected, errors.Is(err, sentinel), sentinel.Error())
+			}
+
+			apiErr := &APIError{}
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, test.status, apiErr.StatusCode)
+
+			if test.expectedMessage != "" {
+				assert.Equal(t, test.expectedMessage, apiErr.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const globalLabelPrefix = "global" // labels visible to everyone (as opposed to personal "my:" labels)
+
+// GetLabels method returns the names of the global labels on a page
+func (a *APIClient) GetLabels(ctx context.Context, pageID int) ([]string, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/label?prefix=%s&limit=%d",
+		a.BaseURL, pageID, globalLabelPrefix, a.pageSize())
+
+	var labels []string
+
+	err := a.getAll(ctx, URL, func(contents []byte) (Pagination, error) {
+		results := LabelResults{}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return Pagination{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		for index := range results.Results {
+			if results.Results[index].Prefix == globalLabelPrefix {
+				labels = append(labels, results.Results[index].Name)
+			}
+		}
+
+		return results.Pagination, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getlabels error for page [%d]: %w", pageID, err)
+	}
+
+	return labels, nil
+}
+
+// AddLabels method adds global labels to a page
+func (a *APIClient) AddLabels(ctx context.Context, pageID int, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	newLabels := make([]Label, 0, len(labels))
+
+	for index := range labels {
+		newLabels = append(newLabels, Label{Prefix: globalLabelPrefix, Name: labels[index]})
+	}
+
+	labelsJSON, err := json.Marshal(newLabels)
+	if err != nil {
+		return fmt.Errorf("addlabels json marshal error: %w", err)
+	}
+
+	URL := fmt.Sprintf("%s/rest/api/content/%d/label", a.BaseURL, pageID)
+
+	req, err := a.newRequest(ctx, http.MethodPost, URL, bytes.NewBuffer(labelsJSON))
+	if err != nil {
+		return fmt.Errorf("addlabels error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	_, err = a.getContents(req)
+	if err != nil {
+		return fmt.Errorf("addlabels error for page [%d]: %w", pageID, err)
+	}
+
+	return nil
+}
+
+// RemoveLabel method removes a global label from a page
+func (a *APIClient) RemoveLabel(ctx context.Context, pageID int, label string) error {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/label?name=%s", a.BaseURL, pageID, url.QueryEscape(label))
+
+	req, err := a.newRequest(ctx, http.MethodDelete, URL, nil)
+	if err != nil {
+		return fmt.Errorf("removelabel error: %w", err)
+	}
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("removelabel error: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("removelabel error for page [%d] label [%s]: %w", pageID, label, newAPIError(resp))
+	}
+
+	return nil
+}
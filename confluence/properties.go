@@ -0,0 +1,168 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ManagedPropertyKey is the key of the content property marking a page as managed by the tool
+const ManagedPropertyKey = "mtc-managed"
+
+// SyncPropertyKey is the key of the content property recording the last synced commit on the root page
+const SyncPropertyKey = "mtc-sync"
+
+// ManifestPropertyKey is the key of the content property recording the pages synced from the repo on the root page
+const ManifestPropertyKey = "mtc-manifest"
+
+// Managed method returns the managed property of a page found with the property expanded
+// and false if the page is not managed by the tool
+func (p Page) Managed() (*ManagedProperty, bool) {
+	if p.Metadata == nil {
+		return nil, false
+	}
+
+	property, ok := p.Metadata.Properties[ManagedPropertyKey]
+	if !ok || len(property.Value) == 0 {
+		return nil, false
+	}
+
+	managed := ManagedProperty{}
+
+	err := json.Unmarshal(property.Value, &managed)
+	if err != nil || managed.Path == "" {
+		return nil, false
+	}
+
+	return &managed, true
+}
+
+// FindManagedPages method returns every page below a page (at any depth)
+// that is marked as managed by the tool
+func (a *APIClient) FindManagedPages(ctx context.Context, pageID int) ([]Page, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/descendant/page?expand=version,ancestors,metadata.properties.%s&limit=%d",
+		a.BaseURL, pageID, ManagedPropertyKey, a.pageSize())
+
+	var pages []Page
+
+	err := a.getAll(ctx, URL, func(contents []byte) (Pagination, error) {
+		results := PageResults{}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return Pagination{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		for index := range results.Results {
+			if _, ok := results.Results[index].Managed(); ok {
+				pages = append(pages, results.Results[index])
+			}
+		}
+
+		return results.Pagination, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("findmanagedpages error for page [%d]: %w", pageID, err)
+	}
+
+	return pages, nil
+}
+
+// GetProperty method returns a content property of a page by key
+// or nil if the page does not have the property
+func (a *APIClient) GetProperty(ctx context.Context, pageID int, key string) (*Property, error) {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/property/%s", a.BaseURL, pageID, key)
+
+	req, err := a.newRequest(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty error: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getproperty error for page [%d] key [%s]: %w", pageID, key, newAPIError(resp))
+	}
+
+	property := Property{}
+
+	err = json.NewDecoder(resp.Body).Decode(&property)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty json decode error: %w", err)
+	}
+
+	return &property, nil
+}
+
+// SetProperty method creates or updates a content property of a page
+func (a *APIClient) SetProperty(ctx context.Context, pageID int, key string, value interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("setproperty json marshal error: %w", err)
+	}
+
+	existing, err := a.GetProperty(ctx, pageID, key)
+	if err != nil {
+		return err
+	}
+
+	property := Property{Key: key, Value: valueJSON}
+
+	method := http.MethodPost
+	URL := fmt.Sprintf("%s/rest/api/content/%d/property", a.BaseURL, pageID)
+
+	if existing != nil {
+		if bytes.Equal(existing.Value, valueJSON) {
+			return nil
+		}
+
+		version := 1
+		if existing.Version != nil {
+			version = existing.Version.Number + 1
+		}
+
+		property.Version = &VersionObj{Number: version}
+
+		method = http.MethodPut
+		URL = fmt.Sprintf("%s/rest/api/content/%d/property/%s", a.BaseURL, pageID, key)
+	}
+
+	propertyJSON, err := json.Marshal(property)
+	if err != nil {
+		return fmt.Errorf("setproperty json marshal error: %w", err)
+	}
+
+	req, err := a.newRequest(ctx, method, URL, bytes.NewBuffer(propertyJSON))
+	if err != nil {
+		return fmt.Errorf("setproperty error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	_, err = a.getContents(req)
+	if err != nil {
+		return fmt.Errorf("setproperty error for page [%d] key [%s]: %w", pageID, key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,286 @@
+package confluence
+
+import (
+	"context"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	defaultRateLimitRetries = 5                // how many times a throttled request is retried
+	defaultThrottleBackoff  = 2 * time.Second  // wait when confluence throttles without a Retry-After
+	maxThrottleBackoff      = 60 * time.Second // never back off longer than this when there is no Retry-After
+	defaultThrottleCooldown = 10 * time.Second // OnThrottle is called at most once in this window
+	recoverAfter            = 50               // consecutive unthrottled responses before growing again
+)
+
+// RateLimiter is a token bucket shared by every request made through a RateLimitedClient
+// so that all workers draw from the same request budget
+type RateLimiter struct {
+	mu          sync.Mutex
+	rate        float64 // tokens added per second - 0 means unlimited
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // no requests are sent before this time (set from Retry-After)
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond on average
+// with bursts of up to burst requests - requestsPerSecond <= 0 disables the budget
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait method blocks until a request is allowed to be sent
+// or returns the context error if ctx is done first
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := r.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause method stops any request being sent for the duration d
+func (r *RateLimiter) Pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(r.pausedUntil) {
+		r.pausedUntil = until
+	}
+}
+
+// reserve method takes a token if one is available and returns 0
+// otherwise it returns how long to wait before trying again
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Before(r.pausedUntil) {
+		return r.pausedUntil.Sub(now)
+	}
+
+	if r.rate <= 0 {
+		return 0
+	}
+
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+}
+
+// RateLimitedClient wraps a HTTPClient so that every request waits for the shared
+// RateLimiter, and requests throttled by confluence (429) are retried once the
+// Retry-After period has passed
+type RateLimitedClient struct {
+	Client     HTTPClient
+	Limiter    *RateLimiter
+	MaxRetries int
+
+	// OnThrottle is called when confluence throttles us or reports we are near the limit
+	OnThrottle func()
+	// OnRecover is called after a run of responses without any throttling
+	OnRecover func()
+	// Cooldown is how long OnThrottle is not called again for once it is called
+	// as the requests in flight are often throttled together
+	Cooldown time.Duration
+
+	mu            sync.Mutex
+	successes     int
+	lastThrottled time.Time // when OnThrottle was last called
+}
+
+// NewRateLimitedClient returns a RateLimitedClient wrapping client with a budget of requestsPerSecond
+func NewRateLimitedClient(client HTTPClient, requestsPerSecond float64, burst int) *RateLimitedClient {
+	return &RateLimitedClient{
+		Client:     client,
+		Limiter:    NewRateLimiter(requestsPerSecond, burst),
+		MaxRetries: defaultRateLimitRetries,
+		Cooldown:   defaultThrottleCooldown,
+	}
+}
+
+// Do method sends the request once the rate limiter allows it, retrying throttled requests
+// the throttled response is returned if the request context ends before the wait confluence asks for
+func (c *RateLimitedClient) Do(req *retryablehttp.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		err := c.Limiter.Wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			return resp, err
+		}
+
+		if !isThrottled(resp) {
+			c.observe(resp)
+			return resp, nil
+		}
+
+		c.throttled()
+
+		if attempt >= c.MaxRetries {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp, attempt)
+
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			log.Printf("confluence is rate limiting requests (status=%d) for %s - longer than the request can wait",
+				resp.StatusCode, wait)
+
+			c.Limiter.Pause(wait)
+
+			return resp, nil
+		}
+
+		log.Printf("confluence is rate limiting requests (status=%d) - pausing all requests for %s",
+			resp.StatusCode, wait)
+
+		c.Limiter.Pause(wait)
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
+
+// observe method checks the rate limit headers of a successful response
+// and tracks how many responses in a row have not been throttled
+func (c *RateLimitedClient) observe(resp *http.Response) {
+	if strings.EqualFold(resp.Header.Get("X-RateLimit-NearLimit"), "true") ||
+		resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		c.throttled()
+		return
+	}
+
+	c.mu.Lock()
+	c.successes++
+	recovered := c.successes >= recoverAfter
+
+	if recovered {
+		c.successes = 0
+	}
+	c.mu.Unlock()
+
+	if recovered && c.OnRecover != nil {
+		c.OnRecover()
+	}
+}
+
+// throttled method resets the run of unthrottled responses
+// and calls OnThrottle unless it was called within the cooldown
+func (c *RateLimitedClient) throttled() {
+	c.mu.Lock()
+	c.successes = 0
+
+	now := time.Now()
+	cooling := !c.lastThrottled.IsZero() && now.Sub(c.lastThrottled) < c.Cooldown
+
+	if !cooling {
+		c.lastThrottled = now
+	}
+	c.mu.Unlock()
+
+	if !cooling && c.OnThrottle != nil {
+		c.OnThrottle()
+	}
+}
+
+// isThrottled function returns true if confluence rejected the request due to rate limiting
+func isThrottled(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != ""
+}
+
+// retryAfter function returns how long to wait before retrying a throttled request
+// using the Retry-After header (in seconds or as a http date) if confluence sent one - however long it is
+// else an exponential backoff based on the attempt number (up to maxThrottleBackoff)
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	wait := defaultThrottleBackoff * time.Duration(1<<uint(attempt))
+
+	if wait > maxThrottleBackoff {
+		wait = maxThrottleBackoff
+	}
+
+	if header := resp.Header.Get("Retry-After"); header != "" {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(header); err == nil {
+			wait = time.Until(date)
+		}
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait
+}
+
+// OnThrottle method registers functions to be called when confluence starts throttling
+// requests (throttled) and when it has stopped for a while (recovered)
+// it does nothing if the client is not a RateLimitedClient
+func (a *APIClient) OnThrottle(throttled, recovered func()) {
+	limited, ok := a.Client.(*RateLimitedClient)
+	if !ok {
+		return
+	}
+
+	limited.OnThrottle = throttled
+	limited.OnRecover = recovered
+}
+
+// checkRetry function is the retry policy for the retryablehttp client wrapped by a RateLimitedClient
+// it is the default policy except that throttled requests are handed back to the RateLimitedClient
+// which waits for the shared Retry-After period instead of each request backing off on its own
+func checkRetry(policy retryablehttp.CheckRetry) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if err == nil && resp != nil && isThrottled(resp) {
+			return false, nil
+		}
+
+		return policy(ctx, resp, err)
+	}
+}
@@ -0,0 +1,175 @@
+package confluence
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencemocks"
+)
+
+func TestRateLimitedClient_Do(t *testing.T) {
+	asserts := assert.New(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	gomock.InOrder(
+		mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"0"}},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil),
+		mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil),
+	)
+
+	var throttled int
+
+	client := NewRateLimitedClient(mock, 0, 1)
+	client.OnThrottle = func() { throttled++ }
+
+	req, err := retryablehttp.NewRequest(http.MethodGet, "https://example.com", nil)
+	asserts.NoError(err)
+
+	resp, err := client.Do(req)
+	asserts.NoError(err)
+	asserts.Equal(http.StatusOK, resp.StatusCode)
+	asserts.Equal(1, throttled)
+}
+
+// throttledResponse function returns a response throttled by confluence with the Retry-After header
+func throttledResponse(retryAfter string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{retryAfter}},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+}
+
+// TestRateLimitedClient_ThrottleCooldown checks OnThrottle is called once for the responses
+// throttled within the cooldown - and again once the cooldown has passed
+func TestRateLimitedClient_ThrottleCooldown(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+	mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(*retryablehttp.Request) (*http.Response, error) {
+		return throttledResponse("0"), nil
+	}).Times(6)
+
+	var throttled int
+
+	client := NewRateLimitedClient(mock, 0, 1)
+	client.MaxRetries = 2
+	client.OnThrottle = func() { throttled++ }
+
+	req, err := retryablehttp.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, 1, throttled)
+
+	client.Cooldown = 0
+
+	_, err = client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, throttled)
+}
+
+// TestRateLimitedClient_RetryAfterDeadline checks a throttled request is not retried when the
+// Retry-After is longer than its context allows - but the other requests are still paused for it
+func TestRateLimitedClient_RetryAfterDeadline(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+	mock.EXPECT().Do(gomock.Any()).Return(throttledResponse("3600"), nil)
+
+	client := NewRateLimitedClient(mock, 0, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Greater(t, client.Limiter.reserve(), 59*time.Minute)
+}
+
+func TestRetryAfter(t *testing.T) {
+	inputs := []struct {
+		name     string
+		header   string
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			name:     "seconds",
+			header:   "7",
+			expected: 7 * time.Second,
+		},
+		{
+			name:     "no header backs off exponentially",
+			attempt:  2,
+			expected: 4 * defaultThrottleBackoff,
+		},
+		{
+			name:     "no header backs off up to the max",
+			attempt:  10,
+			expected: maxThrottleBackoff,
+		},
+		{
+			name:     "long wait is honoured",
+			header:   "3600",
+			expected: time.Hour,
+		},
+		{
+			name:     "date in the past",
+			header:   "Wed, 21 Oct 2015 07:28:00 GMT",
+			expected: 0,
+		},
+	}
+
+	for _, test := range inputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Retry-After", test.header)
+			}
+
+			assert.Equal(t, test.expected, retryAfter(resp, test.attempt))
+		})
+	}
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.Pause(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
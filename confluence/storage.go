@@ -0,0 +1,73 @@
+package confluence
+
+// storage - comparing pages in the storage format the way confluence stores them
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PageUnchanged function returns true if the updated page has the same title as the original page
+// (when the original title is known) and the same storage format body once both are in canonical form
+func PageUnchanged(original, updated Page) bool {
+	if original.Title != "" && original.Title != updated.Title {
+		return false
+	}
+
+	return canonicalStorage(original.Body.Storage.Value) == canonicalStorage(updated.Body.Storage.Value)
+}
+
+// canonicalStorage function returns the storage format in a canonical form - confluence reorders attributes,
+// self-closes empty elements, changes entities and the whitespace between elements when it stores a page
+// so these are all made the same - the value is returned trimmed if it cannot be read
+func canonicalStorage(value string) string {
+	decoder := xml.NewDecoder(strings.NewReader("<storage>" + value + "</storage>"))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var canonical strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return canonical.String()
+		}
+
+		if err != nil {
+			return strings.TrimSpace(value)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			attributes := make([]string, 0, len(token.Attr))
+
+			for _, attribute := range token.Attr {
+				attributes = append(attributes, qualifiedName(attribute.Name)+"="+strconv.Quote(attribute.Value))
+			}
+
+			sort.Strings(attributes)
+
+			canonical.WriteString("<" + strings.Join(append([]string{qualifiedName(token.Name)}, attributes...), " ") + ">")
+		case xml.EndElement:
+			canonical.WriteString("</" + qualifiedName(token.Name) + ">")
+		case xml.CharData:
+			if strings.TrimSpace(string(token)) != "" { // text is kept as it is (it can be the body of a code block)
+				canonical.WriteString(strconv.Quote(string(token)))
+			}
+		}
+	}
+}
+
+// qualifiedName function returns the name of an element or attribute with its prefix (e.g. ac:structured-macro)
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
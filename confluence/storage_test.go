@@ -0,0 +1,69 @@
+package confluence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageUnchanged(t *testing.T) {
+	page := func(title, body string) Page {
+		return Page{Title: title, Body: BodyObj{Storage: StorageObj{Value: body, Representation: "storage"}}}
+	}
+
+	rendered := `<h1>Setup</h1>
+<p>see <a href="/spaces/SPACE/pages/7" data-linked-resource-id="7">the guide</a> &amp; &quot;more&quot;<br/></p>
+<ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[  indented
+code]]></ac:plain-text-body></ac:structured-macro>`
+
+	testInputs := []struct {
+		name      string
+		original  Page
+		unchanged bool
+	}{
+		{
+			name:      "the same page",
+			original:  page("Setup", rendered),
+			unchanged: true,
+		},
+		{
+			name: "stored by confluence",
+			original: page("Setup", `<h1>Setup</h1><p>see <a data-linked-resource-id="7" href="/spaces/SPACE/pages/7">`+
+				`the guide</a> &amp; "more"<br /></p><ac:structured-macro ac:name="code"><ac:plain-text-body>`+
+				`<![CDATA[  indented
+code]]></ac:plain-text-body></ac:structured-macro>`),
+			unchanged: true,
+		},
+		{
+			name:      "the title is not known",
+			original:  page("", rendered),
+			unchanged: true,
+		},
+		{
+			name:     "retitled",
+			original: page("Installation", rendered),
+		},
+		{
+			name:     "the text changed",
+			original: page("Setup", `<h1>Setup</h1><p>see the guide</p>`),
+		},
+		{
+			name: "the code indentation changed",
+			original: page("Setup", `<h1>Setup</h1><p>see <a href="/spaces/SPACE/pages/7" data-linked-resource-id="7">`+
+				`the guide</a> &amp; "more"<br/></p><ac:structured-macro ac:name="code"><ac:plain-text-body>`+
+				`<![CDATA[indented
+code]]></ac:plain-text-body></ac:structured-macro>`),
+		},
+		{
+			name:     "an empty page",
+			original: page("Setup", ""),
+		},
+	}
+
+	for _, test := range testInputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.unchanged, PageUnchanged(test.original, page("Setup", rendered)))
+		})
+	}
+}
@@ -1,8 +1,29 @@
 package confluence
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
 // PageResults contains the returned page values
 type PageResults struct {
 	Results []Page `json:"results"`
+	Pagination
+}
+
+// Pagination contains the pagination values returned with a set of results
+type Pagination struct {
+	Start int      `json:"start,omitempty"`
+	Limit int      `json:"limit,omitempty"`
+	Size  int      `json:"size,omitempty"`
+	Links LinksObj `json:"_links,omitempty"`
+}
+
+// LinksObj contains the pagination links returned with a set of results
+type LinksObj struct {
+	Base string `json:"base,omitempty"`
+	Next string `json:"next,omitempty"`
 }
 
 // Page holds returned confluence data
@@ -15,6 +36,12 @@ type Page struct {
 	Version   VersionObj    `json:"version,omitempty"`
 	Ancestors []AncestorObj `json:"ancestors,omitempty"`
 	Body      BodyObj       `json:"body,omitempty"`
+	Metadata  *MetadataObj  `json:"metadata,omitempty"`
+}
+
+// MetadataObj contains the content properties expanded when finding pages
+type MetadataObj struct {
+	Properties map[string]Property `json:"properties,omitempty"`
 }
 
 // AncestorObj contains the page ID of a parent page
@@ -22,6 +49,42 @@ type AncestorObj struct {
 	ID int `json:"id,omitempty"`
 }
 
+// UnmarshalJSON method reads the ancestor page ID which confluence returns as a string
+func (a *AncestorObj) UnmarshalJSON(data []byte) error {
+	var ancestor struct {
+		ID json.Number `json:"id"`
+	}
+
+	err := json.Unmarshal(data, &ancestor)
+	if err != nil {
+		return err
+	}
+
+	if ancestor.ID == "" {
+		a.ID = 0
+		return nil
+	}
+
+	id, err := strconv.Atoi(ancestor.ID.String())
+	if err != nil {
+		return fmt.Errorf("ancestor id [%s] is not a number: %w", ancestor.ID, err)
+	}
+
+	a.ID = id
+
+	return nil
+}
+
+// ParentID method returns the page ID of the page's direct parent
+// (the last of its ancestors) or 0 if the page has no known parent
+func (p Page) ParentID() int {
+	if len(p.Ancestors) == 0 {
+		return 0
+	}
+
+	return p.Ancestors[len(p.Ancestors)-1].ID
+}
+
 // SpaceObj contains the confluence space value
 type SpaceObj struct {
 	Key string `json:"key,omitempty"`
@@ -42,3 +105,62 @@ type StorageObj struct {
 type VersionObj struct {
 	Number int `json:"number"`
 }
+
+// LabelResults contains the returned label values
+type LabelResults struct {
+	Results []Label `json:"results"`
+	Pagination
+}
+
+// Label holds a confluence label
+type Label struct {
+	ID     string `json:"id,omitempty"`
+	Prefix string `json:"prefix"`
+	Name   string `json:"name"`
+}
+
+// Property holds a confluence content property
+type Property struct {
+	ID      string          `json:"id,omitempty"`
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Version *VersionObj     `json:"version,omitempty"`
+}
+
+// ManagedProperty is the value of the content property stored on every page
+// created by the tool - pages without it were not created by the tool
+// Hash is the sha256 of the body the tool rendered and Version the version of the page the tool left it at
+// (a page with another version was edited since) - Labels are the labels the tool applied to the page
+// (only these are ever removed from it, so labels added by hand are kept)
+type ManagedProperty struct {
+	Repo        string   `json:"repo"`
+	Path        string   `json:"path"`
+	Hash        string   `json:"hash"`
+	ToolVersion string   `json:"toolVersion"`
+	Version     int      `json:"version,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// SyncProperty is the value of the content property stored on the tool's root page
+// recording the last commit of the repo that was synced to confluence
+type SyncProperty struct {
+	Repo   string `json:"repo"`
+	Commit string `json:"commit"`
+}
+
+// ManifestProperty is the value of the content property stored on the tool's root page
+// recording the page synced from each file of the repo (by managed path) on the last run
+type ManifestProperty struct {
+	Repo  string                  `json:"repo"`
+	Pages map[string]ManifestPage `json:"pages"`
+}
+
+// ManifestPage is the page synced from a file - its ID, the version it was found at, the sha256 of its body
+// and the title and parent page it was given
+type ManifestPage struct {
+	ID       int    `json:"id"`
+	Version  int    `json:"version"`
+	Hash     string `json:"hash"`
+	Title    string `json:"title"`
+	ParentID int    `json:"parentId"`
+}
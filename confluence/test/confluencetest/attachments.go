@@ -0,0 +1,166 @@
+package confluencetest
+
+// attachments - handlers for listing, uploading and removing attachments
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+const (
+	attachmentPrefix = "att"    // attachment IDs start with this prefix (page IDs are numbers)
+	maxUploadMemory  = 32 << 20 // multipart uploads above this size are buffered to disk
+)
+
+// attachmentJSON is an attachment as confluence returns it
+type attachmentJSON struct {
+	confluence.Attachment
+	Version confluence.VersionObj `json:"version"`
+}
+
+// newAttachmentJSON function returns the attachment as confluence returns it
+func newAttachmentJSON(attachment Attachment) attachmentJSON {
+	return attachmentJSON{
+		Attachment: confluence.Attachment{
+			ID:    attachment.ID,
+			Title: attachment.Title,
+			Metadata: confluence.AttachmentMetadata{
+				Comment:   attachment.Comment,
+				MediaType: http.DetectContentType(attachment.Data),
+			},
+			Extensions: confluence.AttachmentExtensions{
+				FileSize: int64(len(attachment.Data)),
+				Comment:  attachment.Comment,
+			},
+		},
+		Version: confluence.VersionObj{Number: attachment.Version},
+	}
+}
+
+// listAttachments method handles GET /rest/api/content/{id}/child/attachment
+// optionally filtered to a single file with ?filename=
+func (s *Server) listAttachments(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	filename := r.URL.Query().Get("filename")
+
+	found := []attachmentJSON{}
+
+	for index := range page.Attachments {
+		if filename == "" || page.Attachments[index].Title == filename {
+			found = append(found, newAttachmentJSON(page.Attachments[index]))
+		}
+	}
+
+	start, end, results := s.paginate(r, len(found))
+	results.Results = found[start:end]
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// uploadAttachment method handles POST /rest/api/content/{id}/child/attachment (a new attachment)
+// and POST /rest/api/content/{id}/child/attachment/{attachmentID}/data (a new version of an attachment)
+// nolint: gocyclo // one check per rule confluence applies
+func (s *Server) uploadAttachment(w http.ResponseWriter, r *http.Request, id int, attachmentID string) {
+	if r.Header.Get("X-Atlassian-Token") != "no-check" {
+		writeError(w, http.StatusForbidden, "XSRF check failed")
+		return
+	}
+
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	err := r.ParseMultipartForm(maxUploadMemory)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not parse the multipart request: "+err.Error())
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "No file was uploaded: "+err.Error())
+		return
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not read the uploaded file: "+err.Error())
+		return
+	}
+
+	index := page.attachment(func(attachment Attachment) bool {
+		if attachmentID != "" {
+			return attachment.ID == attachmentID
+		}
+
+		return attachment.Title == header.Filename
+	})
+
+	switch {
+	case attachmentID == "" && index != -1:
+		writeError(w, http.StatusBadRequest, "Cannot add a new attachment with same file name as an existing attachment: "+
+			header.Filename)
+
+		return
+	case attachmentID != "" && index == -1:
+		writeError(w, http.StatusNotFound, "No attachment found with id: "+attachmentID)
+		return
+	case index == -1:
+		page.Attachments = append(page.Attachments, Attachment{
+			ID:    fmt.Sprintf("%s%d", attachmentPrefix, s.nextID),
+			Title: header.Filename,
+		})
+
+		s.nextID++
+		index = len(page.Attachments) - 1
+	}
+
+	attachment := &page.Attachments[index]
+	attachment.Data = data
+	attachment.Comment = r.FormValue("comment")
+	attachment.Version++
+
+	writeJSON(w, http.StatusOK, results{Results: []attachmentJSON{newAttachmentJSON(*attachment)}, Size: 1})
+}
+
+// deleteAttachment method handles DELETE /rest/api/content/{attachmentID}
+func (s *Server) deleteAttachment(w http.ResponseWriter, attachmentID string) {
+	for _, page := range s.pages {
+		index := page.attachment(func(attachment Attachment) bool { return attachment.ID == attachmentID })
+		if index == -1 {
+			continue
+		}
+
+		page.Attachments = append(page.Attachments[:index], page.Attachments[index+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "No content found with id: "+attachmentID)
+}
+
+// attachment method returns the index of the first attachment on the page matching match (or -1)
+func (p *Page) attachment(match func(attachment Attachment) bool) int {
+	for index := range p.Attachments {
+		if match(p.Attachments[index]) {
+			return index
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,327 @@
+package confluencetest
+
+// content - handlers for creating, finding, updating, moving and removing pages
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+const propertyExpand = "metadata.properties."
+
+// contentJSON is a page as confluence returns it - ancestor IDs are strings
+type contentJSON struct {
+	ID        string                  `json:"id"`
+	Type      string                  `json:"type"`
+	Status    string                  `json:"status"`
+	Title     string                  `json:"title"`
+	Space     confluence.SpaceObj     `json:"space"`
+	Version   *confluence.VersionObj  `json:"version,omitempty"`
+	Ancestors []idJSON                `json:"ancestors,omitempty"`
+	Body      *confluence.BodyObj     `json:"body,omitempty"`
+	Metadata  *confluence.MetadataObj `json:"metadata,omitempty"`
+}
+
+// idJSON is a reference to another page
+type idJSON struct {
+	ID string `json:"id"`
+}
+
+// expanded function returns the fields requested with the expand query parameter
+func expanded(r *http.Request) map[string]bool {
+	fields := map[string]bool{}
+
+	for _, field := range strings.Split(r.URL.Query().Get("expand"), ",") {
+		fields[strings.TrimSpace(field)] = true
+	}
+
+	return fields
+}
+
+// content method returns the page as confluence returns it with the expand fields requested
+func (s *Server) content(page *Page, expand map[string]bool) contentJSON {
+	content := contentJSON{
+		ID:     strconv.Itoa(page.ID),
+		Type:   "page",
+		Status: page.Status,
+		Title:  page.Title,
+		Space:  confluence.SpaceObj{Key: s.Space},
+	}
+
+	if expand["version"] {
+		content.Version = &confluence.VersionObj{Number: page.Version}
+	}
+
+	if expand["ancestors"] {
+		for _, id := range s.ancestors(page) {
+			content.Ancestors = append(content.Ancestors, idJSON{ID: strconv.Itoa(id)})
+		}
+	}
+
+	if expand["body.storage"] {
+		content.Body = &confluence.BodyObj{Storage: confluence.StorageObj{Value: page.Body, Representation: "storage"}}
+	}
+
+	for field := range expand {
+		key := strings.TrimPrefix(field, propertyExpand)
+		if key == field {
+			continue
+		}
+
+		if content.Metadata == nil {
+			content.Metadata = &confluence.MetadataObj{Properties: map[string]confluence.Property{}}
+		}
+
+		if property, ok := page.property(key); ok {
+			content.Metadata.Properties[key] = property
+		}
+	}
+
+	return content
+}
+
+// contents method returns the pages as confluence returns them with the expand fields requested
+func (s *Server) contents(pages []*Page, expand map[string]bool) []contentJSON {
+	found := []contentJSON{}
+
+	for _, page := range pages {
+		found = append(found, s.content(page, expand))
+	}
+
+	return found
+}
+
+// checkBody function returns an error if the page body is not well formed xhtml
+// the check is lenient (html entities and unclosed void elements are allowed) like confluence's own parser
+func checkBody(body string) error {
+	decoder := xml.NewDecoder(strings.NewReader("<body>" + body + "</body>"))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodePage function reads the page json sent to create or update a page
+func decodePage(r *http.Request) (confluence.Page, error) {
+	page := confluence.Page{}
+
+	err := json.NewDecoder(r.Body).Decode(&page)
+
+	return page, err
+}
+
+// searchPages method handles GET /rest/api/content - finding pages in the space by title
+func (s *Server) searchPages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	found := []*Page{}
+
+	if (query.Get("type") == "" || query.Get("type") == "page") &&
+		(query.Get("spaceKey") == "" || query.Get("spaceKey") == s.Space) {
+		for _, page := range s.sorted() {
+			if page.Status == StatusCurrent && (query.Get("title") == "" || query.Get("title") == page.Title) {
+				found = append(found, page)
+			}
+		}
+	}
+
+	start, end, page := s.paginate(r, len(found))
+	page.Results = s.contents(found[start:end], expanded(r))
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// createPage method handles POST /rest/api/content
+func (s *Server) createPage(w http.ResponseWriter, r *http.Request) {
+	request, err := decodePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not parse the request body: "+err.Error())
+		return
+	}
+
+	if status, message := s.checkPage(request, 0); status != 0 {
+		writeError(w, status, message)
+		return
+	}
+
+	page := s.newPage(request.ParentID(), request.Title, request.Body.Storage.Value)
+
+	writeJSON(w, http.StatusOK, s.content(page, map[string]bool{"version": true, "ancestors": true}))
+}
+
+// checkPage method validates a page sent to create or update the page with the ID provided (0 when creating)
+// and returns the status and message to respond with if it is not valid (or a status of 0)
+// nolint: gocyclo // one check per rule confluence applies
+func (s *Server) checkPage(request confluence.Page, id int) (int, string) {
+	if request.Type != "page" {
+		return http.StatusBadRequest, fmt.Sprintf("Content type %s is not supported", request.Type)
+	}
+
+	if request.Space.Key != s.Space {
+		return http.StatusNotFound, "No space with key : " + request.Space.Key
+	}
+
+	if strings.TrimSpace(request.Title) == "" {
+		return http.StatusBadRequest, "Title cannot be empty"
+	}
+
+	if s.titleTaken(request.Title, id) {
+		return http.StatusBadRequest, "A page with this title already exists: " +
+			"A page already exists with the title " + request.Title + " in this space"
+	}
+
+	if parentID := request.ParentID(); parentID != 0 {
+		parent, ok := s.current(parentID)
+		if !ok {
+			return http.StatusNotFound, fmt.Sprintf("No content found with id: %d", parentID)
+		}
+
+		if moving, ok := s.pages[id]; ok && s.isBelow(parent, moving.ID) {
+			return http.StatusBadRequest, fmt.Sprintf("Cannot move page %d under itself", id)
+		}
+	}
+
+	if err := checkBody(request.Body.Storage.Value); err != nil {
+		return http.StatusBadRequest, "Error parsing xhtml: " + err.Error()
+	}
+
+	return 0, ""
+}
+
+// getPage method handles GET /rest/api/content/{id}
+func (s *Server) getPage(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.content(page, expanded(r)))
+}
+
+// updatePage method handles PUT /rest/api/content/{id} - updating the title and body
+// and moving the page if it is sent with a different parent
+func (s *Server) updatePage(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	request, err := decodePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not parse the request body: "+err.Error())
+		return
+	}
+
+	if request.Version.Number != page.Version+1 {
+		writeError(w, http.StatusConflict, fmt.Sprintf(
+			"Version must be incremented on update. Current version is: %d", page.Version))
+
+		return
+	}
+
+	if status, message := s.checkPage(request, id); status != 0 {
+		writeError(w, status, message)
+		return
+	}
+
+	if len(request.Ancestors) > 0 {
+		page.ParentID = request.ParentID()
+	}
+
+	page.Title = request.Title
+	page.Body = request.Body.Storage.Value
+	page.Version++
+
+	writeJSON(w, http.StatusOK, s.content(page, map[string]bool{"version": true, "ancestors": true}))
+}
+
+// deletePage method handles DELETE /rest/api/content/{id} - moving the page to the trash
+// or with ?status=trashed purging a page that is already in the trash
+func (s *Server) deletePage(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.pages[id]
+
+	if r.URL.Query().Get("status") == StatusTrashed {
+		if !ok || page.Status != StatusTrashed {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No trashed content found with id: %d", id))
+			return
+		}
+
+		delete(s.pages, id)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	if !ok || page.Status != StatusCurrent {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	page.Status = StatusTrashed
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// archivePages method handles POST /rest/api/content/archive
+func (s *Server) archivePages(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Pages []struct {
+			ID int `json:"id"`
+		} `json:"pages"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Could not parse the request body: %v", err))
+		return
+	}
+
+	for index := range request.Pages {
+		if _, ok := s.current(request.Pages[index].ID); !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", request.Pages[index].ID))
+			return
+		}
+	}
+
+	for index := range request.Pages {
+		s.pages[request.Pages[index].ID].Status = StatusArchived
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": "archive"})
+}
+
+// listPages method handles GET /rest/api/content/{id}/child/page and /descendant/page
+func (s *Server) listPages(w http.ResponseWriter, r *http.Request, id int, descendants bool) {
+	if _, ok := s.current(id); !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	found := s.children(id)
+	if descendants {
+		found = s.descendants(id)
+	}
+
+	start, end, page := s.paginate(r, len(found))
+	page.Results = s.contents(found[start:end], expanded(r))
+
+	writeJSON(w, http.StatusOK, page)
+}
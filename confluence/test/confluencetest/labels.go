@@ -0,0 +1,106 @@
+package confluencetest
+
+// labels - handlers for listing, adding and removing page labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+const globalLabelPrefix = "global" // every label stored by the fake server is a global label
+
+// listLabels method handles GET /rest/api/content/{id}/label
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	found := []confluence.Label{}
+
+	if prefix := r.URL.Query().Get("prefix"); prefix == "" || prefix == globalLabelPrefix {
+		for _, name := range page.Labels {
+			found = append(found, confluence.Label{ID: name, Prefix: globalLabelPrefix, Name: name})
+		}
+	}
+
+	start, end, results := s.paginate(r, len(found))
+	results.Results = found[start:end]
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// addLabels method handles POST /rest/api/content/{id}/label
+func (s *Server) addLabels(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	labels := []confluence.Label{}
+
+	err := json.NewDecoder(r.Body).Decode(&labels)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Could not parse the request body: "+err.Error())
+		return
+	}
+
+	for index := range labels {
+		name := labels[index].Name
+
+		if name == "" || strings.ContainsAny(name, " :;,.!&()[]{}") {
+			writeError(w, http.StatusBadRequest, "Label name is not valid: "+name)
+			return
+		}
+	}
+
+	for index := range labels {
+		if !page.hasLabel(labels[index].Name) {
+			page.Labels = append(page.Labels, labels[index].Name)
+		}
+	}
+
+	sort.Strings(page.Labels)
+
+	s.listLabels(w, r, id)
+}
+
+// removeLabel method handles DELETE /rest/api/content/{id}/label?name=
+func (s *Server) removeLabel(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+
+	for index := range page.Labels {
+		if page.Labels[index] == name {
+			page.Labels = append(page.Labels[:index], page.Labels[index+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "No label found with name: "+name)
+}
+
+// hasLabel method returns true if the page has the label provided
+func (p *Page) hasLabel(name string) bool {
+	for index := range p.Labels {
+		if p.Labels[index] == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package confluencetest
+
+// properties - handlers for reading, creating and updating content properties
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+// property method returns the content property of the page with the key provided
+func (p *Page) property(key string) (confluence.Property, bool) {
+	value, ok := p.Properties[key]
+	if !ok {
+		return confluence.Property{}, false
+	}
+
+	return confluence.Property{
+		ID:      key + "-" + strconv.Itoa(p.ID),
+		Key:     key,
+		Value:   value,
+		Version: &confluence.VersionObj{Number: p.propertyVersions[key]},
+	}, true
+}
+
+// decodeProperty function reads the property json sent to create or update a property
+func decodeProperty(r *http.Request) (confluence.Property, error) {
+	property := confluence.Property{}
+
+	err := json.NewDecoder(r.Body).Decode(&property)
+	if err == nil && !json.Valid(property.Value) {
+		err = fmt.Errorf("the property value is not valid json")
+	}
+
+	return property, err
+}
+
+// getProperty method handles GET /rest/api/content/{id}/property/{key}
+func (s *Server) getProperty(w http.ResponseWriter, id int, key string) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	property, ok := page.property(key)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Cannot find content property with key: %s", key))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, property)
+}
+
+// createProperty method handles POST /rest/api/content/{id}/property
+func (s *Server) createProperty(w http.ResponseWriter, r *http.Request, id int) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	property, err := decodeProperty(r)
+	if err != nil || property.Key == "" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Could not parse the property: %v", err))
+		return
+	}
+
+	if _, exists := page.Properties[property.Key]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("A content property with key %s already exists", property.Key))
+		return
+	}
+
+	page.Properties[property.Key] = property.Value
+	page.propertyVersions[property.Key] = 1
+
+	s.getProperty(w, id, property.Key)
+}
+
+// updateProperty method handles PUT /rest/api/content/{id}/property/{key}
+func (s *Server) updateProperty(w http.ResponseWriter, r *http.Request, id int, key string) {
+	page, ok := s.current(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("No content found with id: %d", id))
+		return
+	}
+
+	if _, exists := page.Properties[key]; !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Cannot find content property with key: %s", key))
+		return
+	}
+
+	property, err := decodeProperty(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Could not parse the property: %v", err))
+		return
+	}
+
+	if property.Version == nil || property.Version.Number != page.propertyVersions[key]+1 {
+		writeError(w, http.StatusConflict, fmt.Sprintf(
+			"Version must be incremented on update. Current version is: %d", page.propertyVersions[key]))
+
+		return
+	}
+
+	page.Properties[key] = property.Value
+	page.propertyVersions[key]++
+
+	s.getProperty(w, id, key)
+}
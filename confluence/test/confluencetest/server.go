@@ -0,0 +1,267 @@
+// Package confluencetest provides an in-memory fake of the confluence REST API (v1)
+// served with httptest, so syncs can be run end to end and the resulting pages inspected
+package confluencetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+const (
+	contentPath  = "/rest/api/content"
+	defaultLimit = 25 // confluence's own default when no limit is provided
+	firstPageID  = 1000
+	token        = "confluencetest-token"
+)
+
+// Server is a fake confluence holding a single space of pages in memory
+type Server struct {
+	*httptest.Server
+	Space string
+
+	mu       sync.Mutex
+	pages    map[int]*Page
+	nextID   int
+	faults   []*fault
+	requests []string
+}
+
+// fault is a response injected with Fail
+type fault struct {
+	method string
+	path   string
+	status int
+	times  int
+}
+
+// NewServer starts a fake confluence for the space key provided
+// the caller should call Close when finished with it
+func NewServer(space string) *Server {
+	s := &Server{
+		Space:  space,
+		pages:  map[int]*Page{},
+		nextID: firstPageID,
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client returns a confluence.APIClient for the fake server
+// requests are not retried by the http client - throttled requests are retried by the RateLimitedClient
+func (s *Server) Client() *confluence.APIClient {
+	httpClient := retryablehttp.NewClient()
+	httpClient.Logger = nil
+	httpClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		return false, err
+	}
+
+	client := confluence.APIClientWithAuths(confluence.NewRateLimitedClient(httpClient, 0, 1))
+	client.BaseURL = s.URL
+	client.Space = s.Space
+	client.ApiKey = token
+	client.Auth = confluence.BearerAuth{Token: token}
+
+	return client
+}
+
+// Fail makes the next times requests matching method and path respond with status
+// an empty method or path matches any request - e.g. Fail(http.MethodPut, "/rest/api/content/1000", 409, 1)
+// throttled responses (429) are sent with a Retry-After of 0 so they are retried straight away
+func (s *Server) Fail(method, path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{method: method, path: path, status: status, times: times})
+}
+
+// Requests returns every request the server has received as "METHOD path?query"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+// injected method returns the status of the first fault matching the request (or 0)
+func (s *Server) injected(r *http.Request) int {
+	for index, fault := range s.faults {
+		if (fault.method != "" && fault.method != r.Method) || (fault.path != "" && fault.path != r.URL.Path) {
+			continue
+		}
+
+		fault.times--
+		if fault.times <= 0 {
+			s.faults = append(s.faults[:index], s.faults[index+1:]...)
+		}
+
+		return fault.status
+	}
+
+	return 0
+}
+
+// serveHTTP method records the request and sends it to the handler for its path
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+
+	if status := s.injected(r); status != 0 {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+
+		writeError(w, status, fmt.Sprintf("injected %d response", status))
+
+		return
+	}
+
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "This request requires authentication")
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, contentPath) {
+		writeError(w, http.StatusNotFound, "no such endpoint: "+r.URL.Path)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, contentPath), "/"), "/")
+	if segments[0] == "" {
+		segments = nil
+	}
+
+	s.route(w, r, segments)
+}
+
+// route method sends the request to the handler for the path segments after /rest/api/content
+// nolint: gocyclo // a flat list of routes is easier to follow than nested routers
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.searchPages(w, r)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.createPage(w, r)
+	case len(segments) == 1 && segments[0] == "archive" && r.Method == http.MethodPost:
+		s.archivePages(w, r)
+	case len(segments) == 1 && strings.HasPrefix(segments[0], attachmentPrefix) && r.Method == http.MethodDelete:
+		s.deleteAttachment(w, segments[0])
+	case len(segments) >= 1:
+		id, err := strconv.Atoi(segments[0])
+		if err != nil {
+			writeError(w, http.StatusNotFound, "No content found with id: "+segments[0])
+			return
+		}
+
+		s.routePage(w, r, id, segments[1:])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, r.Method+" is not supported on "+r.URL.Path)
+	}
+}
+
+// routePage method sends the request to the handler for the path segments after /rest/api/content/{id}
+// nolint: gocyclo // a flat list of routes is easier to follow than nested routers
+func (s *Server) routePage(w http.ResponseWriter, r *http.Request, id int, segments []string) {
+	path := strings.Join(segments, "/")
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		s.getPage(w, r, id)
+	case path == "" && r.Method == http.MethodPut:
+		s.updatePage(w, r, id)
+	case path == "" && r.Method == http.MethodDelete:
+		s.deletePage(w, r, id)
+	case path == "child/page" && r.Method == http.MethodGet:
+		s.listPages(w, r, id, false)
+	case path == "descendant/page" && r.Method == http.MethodGet:
+		s.listPages(w, r, id, true)
+	case path == "child/attachment" && r.Method == http.MethodGet:
+		s.listAttachments(w, r, id)
+	case path == "child/attachment" && r.Method == http.MethodPost:
+		s.uploadAttachment(w, r, id, "")
+	case len(segments) == 4 && segments[0] == "child" && segments[1] == "attachment" && segments[3] == "data" &&
+		r.Method == http.MethodPost:
+		s.uploadAttachment(w, r, id, segments[2])
+	case path == "label" && r.Method == http.MethodGet:
+		s.listLabels(w, r, id)
+	case path == "label" && r.Method == http.MethodPost:
+		s.addLabels(w, r, id)
+	case path == "label" && r.Method == http.MethodDelete:
+		s.removeLabel(w, r, id)
+	case path == "property" && r.Method == http.MethodPost:
+		s.createProperty(w, r, id)
+	case len(segments) == 2 && segments[0] == "property" && r.Method == http.MethodGet:
+		s.getProperty(w, id, segments[1])
+	case len(segments) == 2 && segments[0] == "property" && r.Method == http.MethodPut:
+		s.updateProperty(w, r, id, segments[1])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, r.Method+" is not supported on "+r.URL.Path)
+	}
+}
+
+// writeJSON function writes value as the json response with the status provided
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError function writes an error response the way confluence does
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"statusCode": status,
+		"message":    message,
+	})
+}
+
+// results is a page of results with the pagination values confluence returns
+type results struct {
+	Results interface{}         `json:"results"`
+	Start   int                 `json:"start"`
+	Limit   int                 `json:"limit"`
+	Size    int                 `json:"size"`
+	Links   confluence.LinksObj `json:"_links"`
+}
+
+// paginate method returns the range of the total results requested with start and limit
+// and the pagination values to return with them - with a next link if there are more results
+func (s *Server) paginate(r *http.Request, total int) (int, int, results) {
+	query := r.URL.Query()
+
+	start, _ := strconv.Atoi(query.Get("start"))
+	if start < 0 || start > total {
+		start = total
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	page := results{Start: start, Limit: limit, Size: end - start, Links: confluence.LinksObj{Base: s.URL}}
+
+	if end < total {
+		query.Set("start", strconv.Itoa(end))
+		page.Links.Next = r.URL.Path + "?" + query.Encode()
+	}
+
+	return start, end, page
+}
@@ -0,0 +1,214 @@
+package confluencetest
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+func contents(title, body string) *markdown.FileContents {
+	return &markdown.FileContents{
+		MetaData:           map[string]interface{}{"title": title},
+		Body:               []byte(body),
+		BodyRepresentation: "storage",
+	}
+}
+
+func TestServer_Pages(t *testing.T) {
+	server := NewServer("SPACE")
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client()
+	client.PageSize = 1 // every listing is paginated
+
+	rootID := server.AddPage(0, "root", "")
+
+	docsID, err := client.CreatePage(ctx, rootID, contents("docs", "<p>docs</p>"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, title := range []string{"b", "a"} {
+		_, err = client.CreatePage(ctx, docsID, contents(title, "<p>"+title+"</p>"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = client.CreatePage(ctx, rootID, contents("a", "<p>again</p>"), false)
+	assert.ErrorIs(t, err, confluence.ErrTitleExists)
+
+	_, err = client.CreatePage(ctx, rootID, contents("broken", "<p>text</div>"), false)
+	assert.ErrorIs(t, err, confluence.ErrInvalidStorageFormat)
+
+	assert.Equal(t, "docs\n  a\n  b\n", server.Tree(rootID))
+
+	children, err := client.FindPage(ctx, strconv.Itoa(docsID), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, children.Results, 2)
+	assert.Equal(t, docsID, children.Results[0].ParentID())
+
+	found, err := client.FindPage(ctx, "a", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "<p>a</p>", found.Results[0].Body.Storage.Value)
+
+	escapedID := server.AddPage(0, "R&D #1 + 100% done", "")
+
+	escaped, err := client.FindPage(ctx, "R&D #1 + 100% done", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, strconv.Itoa(escapedID), escaped.Results[0].ID)
+
+	pageID, err := strconv.Atoi(found.Results[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Fail(http.MethodPut, "/rest/api/content/"+found.Results[0].ID, http.StatusConflict, 1)
+
+	version, err := client.UpdatePage(ctx, pageID, rootID, 1, contents("a", "<p>changed</p>"), *found)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, ok := server.Page(pageID)
+	if !ok {
+		t.Fatalf("page [%d] not found", pageID)
+	}
+
+	assert.Equal(t, 2, version)
+	assert.Equal(t, 2, page.Version)
+
+	found, err = client.FindPage(ctx, "a", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := len(server.Requests())
+
+	version, err = client.UpdatePage(ctx, pageID, rootID, 2, contents("a", "<p>changed</p>"), *found)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version) // unchanged so it is not written
+	assert.Len(t, server.Requests(), requests)
+	assert.Equal(t, "<p>changed</p>", page.Body)
+	assert.Equal(t, "a\ndocs\n  b\n", server.Tree(rootID))
+
+	assert.NoError(t, client.DeletePage(ctx, pageID))
+
+	_, err = client.GetPage(ctx, pageID)
+	assert.ErrorIs(t, err, confluence.ErrNotFound)
+
+	page, _ = server.Page(pageID)
+	assert.Equal(t, StatusTrashed, page.Status)
+}
+
+func TestServer_AttachmentsLabelsAndProperties(t *testing.T) {
+	server := NewServer("SPACE")
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client()
+
+	pageID := server.AddPage(0, "page", "")
+
+	file := filepath.Join(t.TempDir(), "picture.png")
+	assert.NoError(t, os.WriteFile(file, []byte("first"), 0o600))
+
+	uploaded, err := client.UploadAttachment(ctx, file, pageID, false, 0)
+	assert.NoError(t, err)
+	assert.True(t, uploaded)
+
+	uploaded, err = client.UploadAttachment(ctx, file, pageID, false, 0) // unchanged so not uploaded again
+	assert.NoError(t, err)
+	assert.False(t, uploaded)
+
+	assert.NoError(t, os.WriteFile(file, []byte("second"), 0o600))
+
+	uploaded, err = client.UploadAttachment(ctx, file, pageID, false, 0)
+	assert.NoError(t, err)
+	assert.True(t, uploaded)
+
+	page, _ := server.Page(pageID)
+	if len(page.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment but found %d", len(page.Attachments))
+	}
+
+	assert.Equal(t, 2, page.Attachments[0].Version)
+	assert.Equal(t, "second", string(page.Attachments[0].Data))
+
+	attachments, err := client.ListAttachments(ctx, pageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment but found %d", len(attachments))
+	}
+
+	assert.NoError(t, client.DeleteAttachment(ctx, attachments[0].ID))
+
+	assert.NoError(t, client.AddLabels(ctx, pageID, []string{"docs", "api"}))
+	assert.NoError(t, client.RemoveLabel(ctx, pageID, "docs"))
+
+	labels, err := client.GetLabels(ctx, pageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"api"}, labels)
+
+	for _, commit := range []string{"abc", "def"} {
+		assert.NoError(t, client.SetProperty(ctx, pageID, confluence.SyncPropertyKey,
+			confluence.SyncProperty{Repo: "org/repo", Commit: commit}))
+	}
+
+	property, err := client.GetProperty(ctx, pageID, confluence.SyncPropertyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.JSONEq(t, `{"repo":"org/repo","commit":"def"}`, string(property.Value))
+	assert.Equal(t, 2, property.Version.Number)
+
+	page, _ = server.Page(pageID)
+	assert.Empty(t, page.Attachments)
+	assert.Equal(t, []string{"api"}, page.Labels)
+}
+
+func TestServer_Fail(t *testing.T) {
+	server := NewServer("SPACE")
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client()
+
+	pageID := server.AddPage(0, "page", "")
+
+	server.Fail("", "", http.StatusTooManyRequests, 2) // throttled requests are retried
+
+	_, err := client.GetPage(ctx, pageID)
+	assert.NoError(t, err)
+
+	server.Fail(http.MethodGet, "", http.StatusUnauthorized, 1)
+
+	_, err = client.GetPage(ctx, pageID)
+	assert.ErrorIs(t, err, confluence.ErrUnauthorized)
+
+	assert.Len(t, server.Requests(), 4)
+}
@@ -0,0 +1,249 @@
+package confluencetest
+
+// store - the pages held by the fake server and methods for inspecting them
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// page statuses
+const (
+	StatusCurrent  = "current"
+	StatusTrashed  = "trashed"
+	StatusArchived = "archived"
+)
+
+// Page is a page stored by the fake server
+type Page struct {
+	ID          int
+	Title       string
+	ParentID    int    // 0 for pages at the top of the space
+	Status      string // StatusCurrent, StatusTrashed or StatusArchived
+	Version     int
+	Body        string
+	Labels      []string
+	Properties  map[string]json.RawMessage
+	Attachments []Attachment
+
+	propertyVersions map[string]int
+}
+
+// Attachment is a file attached to a page
+type Attachment struct {
+	ID      string
+	Title   string
+	Comment string
+	Data    []byte
+	Version int
+}
+
+// AddPage adds a page to the space (e.g. the page the tool is pointed at) and returns its ID
+// a parentID of 0 adds the page at the top of the space
+func (s *Server) AddPage(parentID int, title, body string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.newPage(parentID, title, body).ID
+}
+
+// Page returns the page with the ID provided, whatever its status
+func (s *Server) Page(id int) (Page, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[id]
+	if !ok {
+		return Page{}, false
+	}
+
+	return page.snapshot(), true
+}
+
+// PageByTitle returns the current page with the title provided
+func (s *Server) PageByTitle(title string) (Page, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, page := range s.sorted() {
+		if page.Status == StatusCurrent && page.Title == title {
+			return page.snapshot(), true
+		}
+	}
+
+	return Page{}, false
+}
+
+// Pages returns every current page in the space in the order they were created
+func (s *Server) Pages() []Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pages := []Page{}
+
+	for _, page := range s.sorted() {
+		if page.Status == StatusCurrent {
+			pages = append(pages, page.snapshot())
+		}
+	}
+
+	return pages
+}
+
+// Children returns the current pages directly under a page sorted by title
+func (s *Server) Children(id int) []Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pages := []Page{}
+
+	for _, page := range s.children(id) {
+		pages = append(pages, page.snapshot())
+	}
+
+	return pages
+}
+
+// Tree returns the titles of the current pages under a page, one per line
+// indented by two spaces per level and sorted by title - for comparing against an expected tree
+func (s *Server) Tree(id int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	builder := &strings.Builder{}
+
+	var walk func(id int, depth int)
+
+	walk = func(id int, depth int) {
+		for _, page := range s.children(id) {
+			builder.WriteString(strings.Repeat("  ", depth) + page.Title + "\n")
+			walk(page.ID, depth+1)
+		}
+	}
+
+	walk(id, 0)
+
+	return builder.String()
+}
+
+// snapshot method returns a copy of the page that is safe to read while the server is running
+func (p *Page) snapshot() Page {
+	copied := *p
+	copied.Labels = append([]string{}, p.Labels...)
+	copied.Attachments = append([]Attachment{}, p.Attachments...)
+	copied.Properties = map[string]json.RawMessage{}
+	copied.propertyVersions = nil
+
+	for key, value := range p.Properties {
+		copied.Properties[key] = append(json.RawMessage{}, value...)
+	}
+
+	return copied
+}
+
+// newPage method stores a new current page
+func (s *Server) newPage(parentID int, title, body string) *Page {
+	page := &Page{
+		ID:               s.nextID,
+		Title:            title,
+		ParentID:         parentID,
+		Status:           StatusCurrent,
+		Version:          1,
+		Body:             body,
+		Properties:       map[string]json.RawMessage{},
+		propertyVersions: map[string]int{},
+	}
+
+	s.nextID++
+	s.pages[page.ID] = page
+
+	return page
+}
+
+// current method returns the page with the ID provided if it has not been removed
+func (s *Server) current(id int) (*Page, bool) {
+	page, ok := s.pages[id]
+	if !ok || page.Status != StatusCurrent {
+		return nil, false
+	}
+
+	return page, true
+}
+
+// sorted method returns every page (whatever its status) in the order they were created
+func (s *Server) sorted() []*Page {
+	pages := make([]*Page, 0, len(s.pages))
+
+	for _, page := range s.pages {
+		pages = append(pages, page)
+	}
+
+	sort.Slice(pages, func(i, j int) bool { return pages[i].ID < pages[j].ID })
+
+	return pages
+}
+
+// titleTaken method returns true if a current page other than except has the title provided
+func (s *Server) titleTaken(title string, except int) bool {
+	for _, page := range s.pages {
+		if page.Status == StatusCurrent && page.ID != except && page.Title == title {
+			return true
+		}
+	}
+
+	return false
+}
+
+// children method returns the current pages directly under a page sorted by title
+func (s *Server) children(id int) []*Page {
+	pages := []*Page{}
+
+	for _, page := range s.pages {
+		if page.Status == StatusCurrent && page.ParentID == id {
+			pages = append(pages, page)
+		}
+	}
+
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Title < pages[j].Title })
+
+	return pages
+}
+
+// descendants method returns the current pages below a page at any depth (parents before children)
+func (s *Server) descendants(id int) []*Page {
+	pages := []*Page{}
+
+	for _, child := range s.children(id) {
+		pages = append(pages, child)
+		pages = append(pages, s.descendants(child.ID)...)
+	}
+
+	return pages
+}
+
+// ancestors method returns the IDs of the pages above a page, from the top of the space down
+func (s *Server) ancestors(page *Page) []int {
+	ids := []int{}
+
+	for parent, ok := s.pages[page.ParentID]; ok; parent, ok = s.pages[parent.ParentID] {
+		ids = append([]int{parent.ID}, ids...)
+	}
+
+	return ids
+}
+
+// isBelow method returns true if page is the page with the ID provided or is below it
+func (s *Server) isBelow(page *Page, id int) bool {
+	if page.ID == id {
+		return true
+	}
+
+	for _, ancestor := range s.ancestors(page) {
+		if ancestor == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,778 @@
+package confluence
+
+// v2 - the confluence cloud REST API v2 (/wiki/api/v2) implementation of the api client
+// operations v2 has no endpoint for (uploading attachments, adding/removing labels, reading the properties of many pages,
+// moving and archiving pages) are left to the v1 api of the embedded APIClient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// api versions the client can be created for
+const (
+	APIVersion1 = "v1" // /rest/api - confluence data center and cloud
+	APIVersion2 = "v2" // /wiki/api/v2 - confluence cloud only
+)
+
+// V2Client struct for interacting with confluence cloud using the REST API v2
+type V2Client struct {
+	*APIClient
+
+	spaceMu sync.Mutex
+	spaceID string // the v2 ID of the space - once it has been looked up
+}
+
+// NewV2Client returns a V2Client sending requests with the api client's settings
+func NewV2Client(apiClient *APIClient) *V2Client {
+	return &V2Client{APIClient: apiClient}
+}
+
+// v2Links contains the cursor link to the next page of v2 results
+type v2Links struct {
+	Next string `json:"next"`
+}
+
+// v2Page is a page as returned by the v2 api
+type v2Page struct {
+	ID       string     `json:"id"`
+	Status   string     `json:"status"`
+	Title    string     `json:"title"`
+	ParentID string     `json:"parentId"`
+	Version  VersionObj `json:"version"`
+	Body     struct {
+		Storage StorageObj `json:"storage"`
+	} `json:"body"`
+}
+
+// v2PageResults contains a page of v2 page results
+type v2PageResults struct {
+	Results []v2Page `json:"results"`
+	Links   v2Links  `json:"_links"`
+}
+
+// v2PageRequest is the body sent to create or update a page with the v2 api
+type v2PageRequest struct {
+	ID       string        `json:"id,omitempty"`
+	SpaceID  string        `json:"spaceId"`
+	Status   string        `json:"status"`
+	Title    string        `json:"title"`
+	ParentID string        `json:"parentId,omitempty"`
+	Body     v2BodyRequest `json:"body"`
+	Version  *VersionObj   `json:"version,omitempty"`
+}
+
+// v2BodyRequest is the page body sent with a v2PageRequest
+type v2BodyRequest struct {
+	Representation string `json:"representation"`
+	Value          string `json:"value"`
+}
+
+// page method converts a v2 page to the Page used by the rest of the tool
+func (p v2Page) page() Page {
+	page := Page{
+		ID:      p.ID,
+		Type:    "page",
+		Status:  p.Status,
+		Title:   p.Title,
+		Version: p.Version,
+		Body:    BodyObj{Storage: p.Body.Storage},
+	}
+
+	if parentID, err := strconv.Atoi(p.ParentID); err == nil {
+		page.Ancestors = []AncestorObj{{ID: parentID}}
+	}
+
+	return page
+}
+
+// v2URL method returns the v2 api URL for path
+// the v2 api is always under /wiki, whether or not the base URL includes it
+func (v *V2Client) v2URL(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(v.BaseURL, "/"), "/wiki") + "/wiki/api/v2" + path
+}
+
+// nextURL method returns the URL of the next page of v2 results or "" if there are no more
+func (v *V2Client) nextURL(links v2Links) string {
+	if links.Next == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(links.Next, "http") {
+		return links.Next
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(v.BaseURL, "/"), "/wiki") + links.Next
+}
+
+// getAllV2 method requests URL and every following page of results (using the cursor links)
+// passing the body of each response to collect - which returns the links to the next results
+func (v *V2Client) getAllV2(ctx context.Context, URL string, collect func(contents []byte) (v2Links, error)) error {
+	for URL != "" {
+		req, err := v.newRequest(ctx, http.MethodGet, URL, nil)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Accept", "application/json")
+
+		contents, err := v.getContents(req)
+		if err != nil {
+			return err
+		}
+
+		links, err := collect(contents)
+		if err != nil {
+			return err
+		}
+
+		next := v.nextURL(links)
+		if next == URL {
+			return fmt.Errorf("next page of results has the same url as the current page [%s]", next)
+		}
+
+		URL = next
+	}
+
+	return nil
+}
+
+// getPages method returns every page listed by URL
+func (v *V2Client) getPages(ctx context.Context, URL string) ([]Page, error) {
+	var pages []Page
+
+	err := v.getAllV2(ctx, URL, func(contents []byte) (v2Links, error) {
+		results := v2PageResults{}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return v2Links{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		for index := range results.Results {
+			pages = append(pages, results.Results[index].page())
+		}
+
+		return results.Links, nil
+	})
+
+	return pages, err
+}
+
+// withManaged method sets the managed property of each page that has one
+// as v2 page listings cannot expand content properties
+func (v *V2Client) withManaged(ctx context.Context, pages []Page) ([]Page, error) {
+	properties, err := v.managedProperties(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range pages {
+		if property, ok := properties[pages[index].ID]; ok {
+			pages[index].Metadata = &MetadataObj{Properties: map[string]Property{ManagedPropertyKey: property}}
+		}
+	}
+
+	return pages, nil
+}
+
+// managedProperties method returns the managed property of each of the pages that has one (by page ID)
+// the pages are searched for by ID with the v1 api, which can expand content properties,
+// a page size at a time - rather than a request for the properties of each page
+func (v *V2Client) managedProperties(ctx context.Context, pages []Page) (map[string]Property, error) {
+	properties := map[string]Property{}
+
+	for start := 0; start < len(pages); start += v.pageSize() {
+		end := start + v.pageSize()
+		if end > len(pages) {
+			end = len(pages)
+		}
+
+		ids := make([]string, 0, end-start)
+
+		for index := start; index < end; index++ {
+			if _, err := strconv.Atoi(pages[index].ID); err != nil {
+				return nil, fmt.Errorf("page id [%s] is not a number: %w", pages[index].ID, err)
+			}
+
+			ids = append(ids, pages[index].ID)
+		}
+
+		URL := fmt.Sprintf("%s/rest/api/content/search?cql=%s&expand=metadata.properties.%s&limit=%d", v.BaseURL,
+			url.QueryEscape("id in ("+strings.Join(ids, ",")+")"), ManagedPropertyKey, v.pageSize())
+
+		err := v.getAll(ctx, URL, func(contents []byte) (Pagination, error) {
+			results := PageResults{}
+
+			err := json.Unmarshal(contents, &results)
+			if err != nil {
+				return Pagination{}, fmt.Errorf("json unmarshal error: %w", err)
+			}
+
+			for index := range results.Results {
+				if results.Results[index].Metadata == nil {
+					continue
+				}
+
+				if property, ok := results.Results[index].Metadata.Properties[ManagedPropertyKey]; ok {
+					properties[results.Results[index].ID] = property
+				}
+			}
+
+			return results.Pagination, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("managed properties error: %w", err)
+		}
+	}
+
+	return properties, nil
+}
+
+// SpaceID method returns the v2 space ID of the client's space
+// it is looked up until it is found once - an error is not kept, so the next call looks it up again
+func (v *V2Client) SpaceID(ctx context.Context) (string, error) {
+	v.spaceMu.Lock()
+	defer v.spaceMu.Unlock()
+
+	if v.spaceID != "" {
+		return v.spaceID, nil
+	}
+
+	URL := v.v2URL("/spaces?keys=" + url.QueryEscape(v.Space))
+
+	req, err := v.newRequest(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("spaceid error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	contents, err := v.getContents(req)
+	if err != nil {
+		return "", fmt.Errorf("spaceid error for space [%s]: %w", v.Space, err)
+	}
+
+	var spaces struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+
+	err = json.Unmarshal(contents, &spaces)
+	if err != nil {
+		return "", fmt.Errorf("spaceid json unmarshal error: %w", err)
+	}
+
+	if len(spaces.Results) == 0 {
+		return "", fmt.Errorf("spaceid error: space [%s] was not found", v.Space)
+	}
+
+	v.spaceID = spaces.Results[0].ID
+
+	return v.spaceID, nil
+}
+
+// newPageRequest method returns the v2 body to create or update a page with the contents
+func (v *V2Client) newPageRequest(ctx context.Context, contents *markdown.FileContents, parentID int) (*v2PageRequest, error) {
+	title, ok := contents.MetaData["title"].(string)
+	if !ok {
+		return nil, fmt.Errorf("page title is empty")
+	}
+
+	spaceID, err := v.SpaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &v2PageRequest{
+		SpaceID: spaceID,
+		Status:  "current",
+		Title:   title,
+		Body: v2BodyRequest{
+			Representation: v2Representation(contents.GetBodyRepresentation()),
+			Value:          string(contents.Body),
+		},
+	}
+
+	if parentID != 0 {
+		page.ParentID = strconv.Itoa(parentID)
+	}
+
+	return page, nil
+}
+
+// v2Representation function returns the v2 body representation to send a page body as
+// v2 does not accept the editor representation - the html is sent as storage format instead
+func v2Representation(representation string) string {
+	if representation == "editor" {
+		return "storage"
+	}
+
+	return representation
+}
+
+// CreatePage method takes root (root page id) and page contents and bool (is page root?)
+// and generates a page in confluence and returns the generated page ID
+func (v *V2Client) CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error) {
+	if contents == nil {
+		return 0, fmt.Errorf("createpage error: contents parameter is nil")
+	}
+
+	parentID := root
+	if isroot {
+		parentID = 0
+	}
+
+	page, err := v.newPageRequest(ctx, contents, parentID)
+	if err != nil {
+		return 0, fmt.Errorf("createpage error: %w", err)
+	}
+
+	log.Printf("start creating page with title [%s]", page.Title)
+
+	pageJSON, err := json.Marshal(page)
+	if err != nil {
+		return 0, fmt.Errorf("createpage json marshal error: %w", err)
+	}
+
+	req, err := v.newRequest(ctx, http.MethodPost, v.v2URL("/pages"), pageJSON)
+	if err != nil {
+		return 0, fmt.Errorf("createpage error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	contentsJSON, err := v.getContents(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create confluence page: %w", err)
+	}
+
+	created := v2Page{}
+
+	err = json.Unmarshal(contentsJSON, &created)
+	if err != nil {
+		return 0, fmt.Errorf("createpage json unmarshal error: %w", err)
+	}
+
+	return strconv.Atoi(created.ID)
+}
+
+// GetPage method returns the current version, parent and body of a page by page ID
+func (v *V2Client) GetPage(ctx context.Context, pageID int) (*Page, error) {
+	req, err := v.newRequest(ctx, http.MethodGet, v.v2URL(fmt.Sprintf("/pages/%d?body-format=storage", pageID)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("getpage error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	contents, err := v.getContents(req)
+	if err != nil {
+		return nil, fmt.Errorf("getpage error for page [%d]: %w", pageID, err)
+	}
+
+	found := v2Page{}
+
+	err = json.Unmarshal(contents, &found)
+	if err != nil {
+		return nil, fmt.Errorf("getpage json unmarshal error: %w", err)
+	}
+
+	page := found.page()
+
+	return &page, nil
+}
+
+// UpdatePage updates a confluence page with our newly created data and increases the
+// version by 1 each time - moving the page under parentID if it is not already under it
+// version conflicts are retried with the latest version up to maxConflictRetries times
+// it returns the version the page was updated to - or 0 if the page was unchanged so it was not written
+func (v *V2Client) UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents,
+	originalPage PageResults) (int, error) {
+	var current *Page
+
+	if len(originalPage.Results) > 0 {
+		current = &originalPage.Results[0]
+	}
+
+	for attempt := 0; attempt <= maxConflictRetries; attempt++ {
+		move := current != nil && parentID != 0 && current.ParentID() != parentID
+
+		newParentID := 0
+		if move {
+			newParentID = parentID
+		}
+
+		page, err := v.newPageRequest(ctx, pageContents, newParentID)
+		if err != nil {
+			return 0, fmt.Errorf("updatepage error: %w", err)
+		}
+
+		page.ID = strconv.Itoa(pageID)
+		page.Version = &VersionObj{Number: int(pageVersion) + 1}
+
+		updated := Page{Title: page.Title, Body: BodyObj{Storage: StorageObj{
+			Value:          page.Body.Value,
+			Representation: page.Body.Representation,
+		}}}
+
+		if current != nil && !move && PageUnchanged(*current, updated) {
+			log.Println("No changes to this page")
+			return 0, nil
+		}
+
+		pageJSON, err := json.Marshal(page)
+		if err != nil {
+			return 0, fmt.Errorf("updatepage json marshal error: %w", err)
+		}
+
+		conflict, err := v.putV2Page(ctx, pageID, pageJSON)
+		if err != nil {
+			return 0, err
+		}
+
+		if !conflict {
+			return int(pageVersion) + 1, nil
+		}
+
+		log.Printf("page [%d] was changed since version [%d] was read - fetching the latest version", pageID, pageVersion)
+
+		current, err = v.GetPage(ctx, pageID)
+		if err != nil {
+			return 0, fmt.Errorf("updatepage failed to fetch page after version conflict: %w", err)
+		}
+
+		pageVersion = int64(current.Version.Number)
+	}
+
+	return 0, &ConflictError{PageID: pageID, Attempts: maxConflictRetries + 1}
+}
+
+// putV2Page method sends the updated page to confluence
+// and returns true if confluence rejected the update due to a version conflict
+func (v *V2Client) putV2Page(ctx context.Context, pageID int, pageJSON []byte) (bool, error) {
+	req, err := v.newRequest(ctx, http.MethodPut, v.v2URL(fmt.Sprintf("/pages/%d", pageID)), pageJSON)
+	if err != nil {
+		return false, fmt.Errorf("updatepage error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("updatepage failed to do the request: %w", err)
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(fmt.Errorf("body close error: %w", err))
+		}
+	}()
+
+	if resp.StatusCode == http.StatusConflict {
+		return true, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("updatepage error: %w", newAPIError(resp))
+	}
+
+	return false, nil
+}
+
+// MovePage method moves a page (and its children) under a new parent page
+func (v *V2Client) MovePage(ctx context.Context, pageID, parentID int) error {
+	URL := fmt.Sprintf("%s/rest/api/content/%d/move/append/%d", v.BaseURL, pageID, parentID)
+
+	req, err := v.newRequest(ctx, http.MethodPut, URL, nil)
+	if err != nil {
+		return fmt.Errorf("movepage error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	_, err = v.getContents(req)
+	if err != nil {
+		return fmt.Errorf("movepage error for page [%d]: %w", pageID, err)
+	}
+
+	return nil
+}
+
+// DeletePage removes a confluence page by page ID using the client's removal mode
+// (trash, purge or archive) - see APIClient.DeletePage
+func (v *V2Client) DeletePage(ctx context.Context, pageID int) error {
+	switch v.RemovalMode {
+	case "", RemoveTrash:
+		return v.doDelete(ctx, v.v2URL(fmt.Sprintf("/pages/%d", pageID)))
+	case RemovePurge:
+		err := v.doDelete(ctx, v.v2URL(fmt.Sprintf("/pages/%d", pageID)))
+		if err != nil {
+			return err
+		}
+
+		return v.doDelete(ctx, v.v2URL(fmt.Sprintf("/pages/%d?purge=true", pageID)))
+	case RemoveArchive:
+		if v.ArchivePageID != 0 {
+			return v.MovePage(ctx, pageID, v.ArchivePageID)
+		}
+
+		return v.archivePage(ctx, pageID)
+	}
+
+	return fmt.Errorf("deletepage error: unknown removal mode [%s]", v.RemovalMode)
+}
+
+// FindPage method finds a page in the space by title (many = false)
+// or the child pages of a page by page ID (many = true)
+// and returns nil if nothing was found
+func (v *V2Client) FindPage(ctx context.Context, title string, many bool) (*PageResults, error) {
+	var (
+		pages []Page
+		err   error
+	)
+
+	if many {
+		pages, err = v.getPages(ctx, v.v2URL(fmt.Sprintf("/pages/%s/children?limit=%d", title, v.pageSize())))
+	} else {
+		var spaceID string
+
+		spaceID, err = v.SpaceID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("find page request error: %w", err)
+		}
+
+		pages, err = v.getPages(ctx, v.v2URL(fmt.Sprintf("/pages?space-id=%s&title=%s&body-format=storage&limit=%d",
+			spaceID, url.QueryEscape(title), v.pageSize())))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("find page request error: %w", err)
+	}
+
+	if len(pages) == 0 { // we want to return nil to skip this result
+		return nil, nil
+	}
+
+	if many { // children are listed without their parent or version
+		parentID, err := strconv.Atoi(title)
+		if err == nil {
+			for index := range pages {
+				pages[index].Ancestors = []AncestorObj{{ID: parentID}}
+			}
+		}
+	}
+
+	pages, err = v.withManaged(ctx, pages)
+	if err != nil {
+		return nil, fmt.Errorf("find page request error: %w", err)
+	}
+
+	return &PageResults{Results: pages, Pagination: Pagination{Size: len(pages)}}, nil
+}
+
+// FindManagedPages method returns every page below a page (at any depth)
+// that is marked as managed by the tool
+func (v *V2Client) FindManagedPages(ctx context.Context, pageID int) ([]Page, error) {
+	pages, err := v.getPages(ctx, v.v2URL(fmt.Sprintf("/pages/%d/descendants?limit=%d", pageID, v.pageSize())))
+	if err != nil {
+		return nil, fmt.Errorf("findmanagedpages error for page [%d]: %w", pageID, err)
+	}
+
+	pages, err = v.withManaged(ctx, pages)
+	if err != nil {
+		return nil, fmt.Errorf("findmanagedpages error for page [%d]: %w", pageID, err)
+	}
+
+	var managed []Page
+
+	for index := range pages {
+		if _, ok := pages[index].Managed(); ok {
+			managed = append(managed, pages[index])
+		}
+	}
+
+	return managed, nil
+}
+
+// GetLabels method returns the names of the global labels on a page
+func (v *V2Client) GetLabels(ctx context.Context, pageID int) ([]string, error) {
+	URL := v.v2URL(fmt.Sprintf("/pages/%d/labels?prefix=%s&limit=%d", pageID, globalLabelPrefix, v.pageSize()))
+
+	var labels []string
+
+	err := v.getAllV2(ctx, URL, func(contents []byte) (v2Links, error) {
+		var results struct {
+			Results []Label `json:"results"`
+			Links   v2Links `json:"_links"`
+		}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return v2Links{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		for index := range results.Results {
+			labels = append(labels, results.Results[index].Name)
+		}
+
+		return results.Links, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getlabels error for page [%d]: %w", pageID, err)
+	}
+
+	return labels, nil
+}
+
+// GetProperty method returns a content property of a page by key
+// or nil if the page does not have the property
+func (v *V2Client) GetProperty(ctx context.Context, pageID int, key string) (*Property, error) {
+	req, err := v.newRequest(ctx, http.MethodGet,
+		v.v2URL(fmt.Sprintf("/pages/%d/properties?key=%s", pageID, url.QueryEscape(key))), nil)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty error: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	contents, err := v.getContents(req)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty error for page [%d] key [%s]: %w", pageID, key, err)
+	}
+
+	var results struct {
+		Results []Property `json:"results"`
+	}
+
+	err = json.Unmarshal(contents, &results)
+	if err != nil {
+		return nil, fmt.Errorf("getproperty json unmarshal error: %w", err)
+	}
+
+	for index := range results.Results {
+		if results.Results[index].Key == key {
+			return &results.Results[index], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// SetProperty method creates or updates a content property of a page
+func (v *V2Client) SetProperty(ctx context.Context, pageID int, key string, value interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("setproperty json marshal error: %w", err)
+	}
+
+	existing, err := v.GetProperty(ctx, pageID, key)
+	if err != nil {
+		return err
+	}
+
+	property := Property{Key: key, Value: valueJSON}
+
+	method := http.MethodPost
+	URL := v.v2URL(fmt.Sprintf("/pages/%d/properties", pageID))
+
+	if existing != nil {
+		if string(existing.Value) == string(valueJSON) {
+			return nil
+		}
+
+		version := 1
+		if existing.Version != nil {
+			version = existing.Version.Number + 1
+		}
+
+		property.Version = &VersionObj{Number: version}
+
+		method = http.MethodPut
+		URL = v.v2URL(fmt.Sprintf("/pages/%d/properties/%s", pageID, existing.ID))
+	}
+
+	propertyJSON, err := json.Marshal(property)
+	if err != nil {
+		return fmt.Errorf("setproperty json marshal error: %w", err)
+	}
+
+	req, err := v.newRequest(ctx, method, URL, propertyJSON)
+	if err != nil {
+		return fmt.Errorf("setproperty error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	_, err = v.getContents(req)
+	if err != nil {
+		return fmt.Errorf("setproperty error for page [%d] key [%s]: %w", pageID, key, err)
+	}
+
+	return nil
+}
+
+// ListAttachments method returns every attachment on a page
+func (v *V2Client) ListAttachments(ctx context.Context, pageID int) ([]Attachment, error) {
+	URL := v.v2URL(fmt.Sprintf("/pages/%d/attachments?limit=%d", pageID, v.pageSize()))
+
+	var attachments []Attachment
+
+	err := v.getAllV2(ctx, URL, func(contents []byte) (v2Links, error) {
+		var results struct {
+			Results []struct {
+				ID        string `json:"id"`
+				Title     string `json:"title"`
+				MediaType string `json:"mediaType"`
+				FileSize  int64  `json:"fileSize"`
+				Comment   string `json:"comment"`
+			} `json:"results"`
+			Links v2Links `json:"_links"`
+		}
+
+		err := json.Unmarshal(contents, &results)
+		if err != nil {
+			return v2Links{}, fmt.Errorf("json unmarshal error: %w", err)
+		}
+
+		for _, result := range results.Results {
+			attachment := Attachment{ID: result.ID, Title: result.Title}
+			attachment.Metadata.Comment = result.Comment
+			attachment.Metadata.MediaType = result.MediaType
+			attachment.Extensions.FileSize = result.FileSize
+
+			attachments = append(attachments, attachment)
+		}
+
+		return results.Links, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listattachments error for page [%d]: %w", pageID, err)
+	}
+
+	return attachments, nil
+}
+
+// DeleteAttachment method moves an attachment to the trash by attachment ID
+func (v *V2Client) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	err := v.doDelete(ctx, v.v2URL("/attachments/"+attachmentID))
+	if err != nil {
+		return fmt.Errorf("deleteattachment error for attachment [%s]: %w", attachmentID, err)
+	}
+
+	return nil
+}
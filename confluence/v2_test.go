@@ -0,0 +1,232 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencemocks"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+func v2Response(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestV2Client_FindPage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/wiki/api/v2/spaces":
+			return v2Response(`{"results":[{"id":"42"}]}`), nil
+		case req.URL.Path == "/wiki/api/v2/pages" && req.URL.Query().Get("cursor") == "":
+			if req.URL.Query().Get("space-id") != "42" {
+				return nil, fmt.Errorf("unexpected space id in %s", req.URL)
+			}
+
+			return v2Response(`{"results":[{"id":"1","title":"page","parentId":"9","version":{"number":3}}],` +
+				`"_links":{"next":"/wiki/api/v2/pages?cursor=abc"}}`), nil
+		case req.URL.Path == "/wiki/api/v2/pages":
+			return v2Response(`{"results":[{"id":"2","title":"page"}],"_links":{}}`), nil
+		case req.URL.Path == "/wiki/rest/api/content/search": // the properties of both pages in one request
+			if req.URL.Query().Get("cql") != "id in (1,2)" {
+				return nil, fmt.Errorf("unexpected cql in %s", req.URL)
+			}
+
+			return v2Response(`{"results":[{"id":"1","metadata":{"properties":{"mtc-managed":` +
+				`{"id":"5","key":"mtc-managed","value":{"repo":"org/repo","path":"docs"}}}}},{"id":"2"}]}`), nil
+		}
+
+		return nil, fmt.Errorf("unexpected request %s", req.URL)
+	}).AnyTimes()
+
+	client := NewV2Client(APIClientWithAuths(mock))
+	client.BaseURL = "https://example.atlassian.net/wiki"
+
+	results, err := client.FindPage(context.Background(), "page", false)
+	assert.NoError(t, err)
+	assert.Len(t, results.Results, 2)
+	assert.Equal(t, 9, results.Results[0].ParentID())
+	assert.Equal(t, 3, results.Results[0].Version.Number)
+
+	managed, ok := results.Results[0].Managed()
+	assert.True(t, ok)
+	assert.Equal(t, "docs", managed.Path)
+
+	_, ok = results.Results[1].Managed()
+	assert.False(t, ok)
+}
+
+// TestV2Client_FindManagedPages checks the managed properties of the pages listed are fetched
+// a page size at a time rather than with a request for each page
+func TestV2Client_FindManagedPages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	var searches []string
+
+	mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/wiki/api/v2/pages/9/descendants":
+			return v2Response(`{"results":[{"id":"1","parentId":"9"},{"id":"2","parentId":"1"},{"id":"3","parentId":"9"}]}`), nil
+		case "/wiki/rest/api/content/search":
+			searches = append(searches, req.URL.Query().Get("cql"))
+
+			if req.URL.Query().Get("cql") != "id in (1,2)" {
+				return v2Response(`{"results":[{"id":"3"}]}`), nil
+			}
+
+			return v2Response(`{"results":[{"id":"2","metadata":{"properties":{"mtc-managed":` +
+				`{"key":"mtc-managed","value":{"repo":"org/repo","path":"docs/setup.md"}}}}}]}`), nil
+		}
+
+		return nil, fmt.Errorf("unexpected request %s", req.URL)
+	}).AnyTimes()
+
+	client := NewV2Client(APIClientWithAuths(mock))
+	client.BaseURL = "https://example.atlassian.net/wiki"
+	client.PageSize = 2
+
+	pages, err := client.FindManagedPages(context.Background(), 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"id in (1,2)", "id in (3)"}, searches)
+	assert.Len(t, pages, 1)
+	assert.Equal(t, "2", pages[0].ID)
+	assert.Equal(t, 1, pages[0].ParentID())
+}
+
+// TestV2Client_SpaceID checks an error looking up the space ID is not kept
+// - and the space ID is only looked up until it is found
+func TestV2Client_SpaceID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	gomock.InOrder(
+		mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil),
+		mock.EXPECT().Do(gomock.Any()).Return(v2Response(`{"results":[{"id":"42"}]}`), nil),
+	)
+
+	client := NewV2Client(APIClientWithAuths(mock))
+	client.BaseURL = "https://example.atlassian.net"
+
+	_, err := client.SpaceID(context.Background())
+	assert.Error(t, err)
+
+	for index := 0; index < 2; index++ {
+		spaceID, err := client.SpaceID(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "42", spaceID)
+	}
+}
+
+// TestV2Client_FindPage_EscapedTitle checks a title with characters that have a meaning in a url
+// is sent as the title query parameter as it is
+func TestV2Client_FindPage_EscapedTitle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	title := "R&D #1 + 100% done"
+
+	mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/wiki/api/v2/spaces":
+			return v2Response(`{"results":[{"id":"42"}]}`), nil
+		case "/wiki/api/v2/pages":
+			if req.URL.Query().Get("title") != title || req.URL.Query().Get("limit") == "" {
+				return nil, fmt.Errorf("unexpected query in %s", req.URL)
+			}
+
+			return v2Response(`{"results":[{"id":"1","title":` + strconv.Quote(title) + `}],"_links":{}}`), nil
+		}
+
+		return v2Response(`{"results":[]}`), nil
+	}).AnyTimes()
+
+	client := NewV2Client(APIClientWithAuths(mock))
+	client.BaseURL = "https://example.atlassian.net/wiki"
+
+	results, err := client.FindPage(context.Background(), title, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, results.Results, 1)
+	assert.Equal(t, title, results.Results[0].Title)
+}
+
+func TestV2Client_CreatePage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := confluencemocks.NewMockHTTPClient(mockCtrl)
+
+	gomock.InOrder(
+		mock.EXPECT().Do(gomock.Any()).Return(v2Response(`{"results":[{"id":"42"}]}`), nil),
+		mock.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *retryablehttp.Request) (*http.Response, error) {
+			body, err := req.BodyBytes()
+			if err != nil {
+				return nil, err
+			}
+
+			page := v2PageRequest{}
+
+			err = json.Unmarshal(body, &page)
+			if err != nil {
+				return nil, err
+			}
+
+			expected := v2PageRequest{
+				SpaceID:  "42",
+				Status:   "current",
+				Title:    "title",
+				ParentID: "7",
+				Body:     v2BodyRequest{Representation: "storage", Value: "<p>text</p>"},
+			}
+
+			if req.URL.Path != "/wiki/api/v2/pages" || page != expected {
+				return nil, fmt.Errorf("unexpected request %s %s", req.URL, body)
+			}
+
+			return v2Response(`{"id":"123"}`), nil
+		}),
+	)
+
+	client := NewV2Client(APIClientWithAuths(mock))
+	client.BaseURL = "https://example.atlassian.net"
+
+	id, err := client.CreatePage(context.Background(), 7, &markdown.FileContents{
+		MetaData: map[string]interface{}{"title": "title"},
+		Body:     []byte("<p>text</p>"),
+	}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 123, id)
+}
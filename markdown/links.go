@@ -0,0 +1,26 @@
+package markdown
+
+// links - the targets of the links in the markdown, so a sync can tell which pages link to a file
+
+import (
+	"regexp"
+)
+
+var (
+	markdownTarget = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)`)
+	htmlTarget     = regexp.MustCompile(`(?i)\b(?:href|src)\s*=\s*"([^"]+)"`)
+)
+
+// Links function returns the targets of the links and images in the markdown as they are written
+// (relative paths, urls or #anchors) - markdown links first, then html links
+func Links(content []byte) []string {
+	var links []string
+
+	for _, pattern := range []*regexp.Regexp{markdownTarget, htmlTarget} {
+		for _, match := range pattern.FindAllSubmatch(content, -1) {
+			links = append(links, string(match[1]))
+		}
+	}
+
+	return links
+}
@@ -14,14 +14,15 @@ import (
 	"strings"
 
 	"github.com/gohugoio/hugo/parser/pageparser"
-	"github.com/xiatechs/markdown-to-confluence/common"
 	m "gitlab.com/golang-commonmark/markdown"
 )
 
-// GrabAuthors - do we want to collect authors?
-var (
-	GrabAuthors bool
-)
+// Options are the settings markdown is rendered to a confluence page with
+type Options struct {
+	GrabAuthors bool   // add the authors of the file (from its git history) to the end of the page
+	BaseURL     string // the confluence base url images are linked from
+	Space       string // the confluence space links to other pages are made in
+}
 
 // FileContents contains information from a file after being parsed from markdown.
 // `Metadata` in the format of a `map[string]interface{}` this can contain title, description, slug etc.
@@ -39,6 +40,48 @@ func (c FileContents) GetBodyRepresentation() string {
 	return c.BodyRepresentation
 }
 
+// Labels returns the labels set in the frontmatter as either `labels` or `tags`
+// (a list or a comma separated string) normalised for use as confluence labels
+func (c FileContents) Labels() []string {
+	var labels []string
+
+	for _, key := range []string{"labels", "tags"} {
+		switch value := c.MetaData[key].(type) {
+		case []interface{}:
+			for index := range value {
+				labels = append(labels, fmt.Sprint(value[index]))
+			}
+		case []string:
+			labels = append(labels, value...)
+		case string:
+			labels = append(labels, strings.Split(value, ",")...)
+		}
+	}
+
+	return NormaliseLabels(labels)
+}
+
+// NormaliseLabels function lower cases labels and replaces whitespace with '-' as confluence
+// labels cannot contain spaces, dropping any empty or duplicate labels
+func NormaliseLabels(labels []string) []string {
+	var normalised []string
+
+	seen := map[string]bool{}
+
+	for index := range labels {
+		label := strings.Join(strings.Fields(strings.ToLower(labels[index])), "-")
+		if label == "" || seen[label] {
+			continue
+		}
+
+		seen[label] = true
+
+		normalised = append(normalised, label)
+	}
+
+	return normalised
+}
+
 // grabtitle function collects the filename of a markdown file
 // and returns it as a string
 //
@@ -175,9 +218,9 @@ func capGit(path string) string {
 	return output
 }
 
-// ParseMarkdown function uses external parsing library to grab markdown contents
-// and return a filecontents object
-func ParseMarkdown(rootID int, content []byte, isIndex bool,
+// ParseMarkdown method uses external parsing library to grab markdown contents
+// and return a filecontents object rendered with the options
+func (options Options) ParseMarkdown(rootID int, content []byte, isIndex bool,
 	pages map[string]string, path, abs, fileName string) (*FileContents, error) {
 	r := bytes.NewReader(content)
 	f := newFileContents()
@@ -193,7 +236,7 @@ func ParseMarkdown(rootID int, content []byte, isIndex bool,
 		fileName = strings.Split(path, "/")[len(strings.Split(path, "/"))-1]
 	}
 
-	f.MetaData["title"] = fileName
+	f.MetaData["title"] = PageTitle(content, fileName)
 
 	value, ok := f.MetaData["title"]
 	if !ok {
@@ -213,9 +256,9 @@ func ParseMarkdown(rootID int, content []byte, isIndex bool,
 	)
 
 	preformatted := md.RenderToString(content)
-	f.Body = stripFrontmatterReplaceURL(preformatted, isIndex, pages, abs, fileName)
+	f.Body = options.stripFrontmatterReplaceURL(preformatted, isIndex, pages, abs, fileName)
 
-	if GrabAuthors {
+	if options.GrabAuthors {
 		f.Body = append(f.Body, []byte(capGit(path))...)
 	}
 
@@ -243,10 +286,10 @@ func linkFilterLogic(item string) bool {
 	return false
 }
 
-// stripFrontmatterReplaceURL function takes in parent page ID and
+// stripFrontmatterReplaceURL method takes in parent page ID and
 // markdown file contents and removes TOML frontmatter, and replaces
 // local URL with relative confluence URL
-func stripFrontmatterReplaceURL(content string,
+func (options Options) stripFrontmatterReplaceURL(content string,
 	isIndex bool, pages map[string]string, abs, fileName string) []byte {
 	var pre string
 
@@ -262,12 +305,12 @@ func stripFrontmatterReplaceURL(content string,
 
 		// correct the local url paths to be absolute paths
 		if strings.Contains(lines[index], "<a href=") && !linkFilterLogic(lines[index]) {
-			lines[index] = relativeURLdetector(lines[index], pages, abs, fileName)
+			lines[index] = options.relativeURLdetector(lines[index], pages, abs, fileName)
 		}
 
 		// set up the local url image links
 		if strings.Contains(lines[index], "<img src=") {
-			lines[index] = URLConverter(pages, lines[index], isIndex, abs)
+			lines[index] = options.URLConverter(pages, lines[index], isIndex, abs)
 		}
 
 		if !frontmatter {
@@ -380,7 +423,7 @@ func (p pages) filter() fpage {
 
 // takes in the absolute URL and will match the relative link to a generated confluence page
 // if this fails, it will just return a template
-func relativeURLdetector(item string, page map[string]string, abs, fileName string) string {
+func (options Options) relativeURLdetector(item string, page map[string]string, abs, fileName string) string {
 	const fail = `<p>[failed during relativeURLdetector]</p>`
 
 	urlLink := strings.Split(item, `</a>`)
@@ -428,14 +471,14 @@ func relativeURLdetector(item string, page map[string]string, abs, fileName stri
 	// replace the relative url in the item with the absolute url
 	splitItem := strings.Split(item, "<a href=")
 
-	return generateLineToReturn(updatedURL, link, splitItem, page)
+	return options.generateLineToReturn(updatedURL, link, splitItem, page)
 }
 
-func generateLineToReturn(updatedURL, link string, splitItem []string, page map[string]string) string {
+func (options Options) generateLineToReturn(updatedURL, link string, splitItem []string, page map[string]string) string {
 	stringToReturn := splitItem[0]
 
 	for i := 1; i < len(splitItem); i++ {
-		link := generateLink(page, updatedURL, link)
+		link := options.generateLink(page, updatedURL, link)
 		extraParts := strings.SplitN(splitItem[i], ">", 2) //nolint:gomnd // only want to split the final part on the first >
 
 		stringToReturn += link
@@ -450,9 +493,9 @@ func generateLineToReturn(updatedURL, link string, splitItem []string, page map[
 	return stringToReturn
 }
 
-func generateLink(page map[string]string, updatedURL string, localLink string) string {
+func (options Options) generateLink(page map[string]string, updatedURL string, localLink string) string {
 	// to format this in confluence we must follow how confluence formats its content in the web frontend
-	a := `<a href="/spaces/` + common.ConfluenceSpace + `/pages/` + page[updatedURL] + localLink + `" `
+	a := `<a href="/spaces/` + options.Space + `/pages/` + page[updatedURL] + localLink + `" `
 
 	b := `data-linked-resource-id="` + page[updatedURL] + `" `
 
@@ -578,11 +621,11 @@ func minimum(a, b, c int) int {
 	return c
 }
 
-// URLConverter function for images to be loaded in to confluence page
+// URLConverter method for images to be loaded in to confluence page
 // (they must be in same directory as markdown to work)
-// this function replaces local url paths in html img links
-// with a confluence path for folder page attachments on parent page
-func URLConverter(page map[string]string, item string, isindex bool, abs string) string {
+// this method replaces local url paths in html img links
+// with a confluence path (from the options base url) for folder page attachments on parent page
+func (options Options) URLConverter(page map[string]string, item string, isindex bool, abs string) string {
 	sliceOne := strings.Split(item, `<img src="`)
 
 	if len(sliceOne) > 1 {
@@ -605,10 +648,10 @@ func URLConverter(page map[string]string, item string, isindex bool, abs string)
 			stringToReturn += `<img class="confluence-embedded-image" loading="lazy" `
 
 			//nolint:lll /// set text
-			stringToReturn += `src="` + common.ConfluenceBaseURL + `/download/attachments/` + page[urlWithoutFile] + `/` + splitURL[len(splitURL)-1] + `" `
+			stringToReturn += `src="` + options.BaseURL + `/download/attachments/` + page[urlWithoutFile] + `/` + splitURL[len(splitURL)-1] + `" `
 
 			//nolint:lll /// set text
-			stringToReturn += `data-image-src="` + common.ConfluenceBaseURL + `/download/attachments/` + page[urlWithoutFile] + `/` + splitURL[len(splitURL)-1] + `" `
+			stringToReturn += `data-image-src="` + options.BaseURL + `/download/attachments/` + page[urlWithoutFile] + `/` + splitURL[len(splitURL)-1] + `" `
 
 			stringToReturn += `data-linked-resource-id="` + page[urlWithoutFile] + `" `
 
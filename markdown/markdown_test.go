@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/xiatechs/markdown-to-confluence/common"
 )
 
 func TestRelativeURLdetector(t *testing.T) {
@@ -62,7 +61,7 @@ func TestRelativeURLdetector(t *testing.T) {
 	for _, test := range testInputs {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			assert.Equal(t, test.expectedoutput, relativeURLdetector(test.arg1, test.arg2, test.arg3, test.filename))
+			assert.Equal(t, test.expectedoutput, Options{}.relativeURLdetector(test.arg1, test.arg2, test.arg3, test.filename))
 		})
 	}
 }
@@ -86,7 +85,8 @@ code line c
 }
 
 func TestParseMarkDown(t *testing.T) {
-	link := common.ConfluenceBaseURL + `/download/attachments//node.png`
+	options := Options{BaseURL: "https://confluence"}
+	link := options.BaseURL + `/download/attachments//node.png`
 	testInputs := []struct {
 		Name     string
 		input    []byte
@@ -99,7 +99,7 @@ func TestParseMarkDown(t *testing.T) {
 This Action will trawl through a repository.`),
 			expected: &FileContents{
 				MetaData: map[string]interface{}{
-					"title": "filename",
+					"title": "Markdown to Confluence Action",
 				},
 				Body: []byte(`<h1>Markdown To Confluence Action</h1>
 <p>This Action will trawl through a repository.</p>`),
@@ -112,7 +112,7 @@ This Action will trawl through a repository.`),
 ![Diagram of action methodology](node.png)`),
 			expected: &FileContents{
 				MetaData: map[string]interface{}{
-					"title": "filename",
+					"title": "Markdown to Confluence Action",
 				},
 				//nolint:lll /// test data
 				Body: []byte(`<h1>Markdown To Confluence Action</h1>
@@ -124,7 +124,7 @@ This Action will trawl through a repository.`),
 	for _, test := range testInputs {
 		test := test
 		t.Run(test.Name, func(t *testing.T) {
-			result, _ := ParseMarkdown(0, test.input, false, map[string]string{}, ".", "/abs/path", "filename")
+			result, _ := options.ParseMarkdown(0, test.input, false, map[string]string{}, ".", "/abs/path", "filename")
 			assert.Equal(t, test.expected, result)
 		})
 	}
@@ -149,13 +149,82 @@ test description`)
 			"date":        "2021-03-10",
 			"description": "A guide on how to use the markdown to confluence action",
 			"slug":        "markdown-to-confluence-guide",
-			"title":       "filename",
+			"title":       "Markdown to Confluence Action Guide",
 		},
 		Body: []byte(`<h1>Test Content</h1>
 <p>test description</p>`),
 	}
 
-	out, err := ParseMarkdown(0, testContent, false, map[string]string{}, ".", "/abs/path", "filename")
+	out, err := Options{}.ParseMarkdown(0, testContent, false, map[string]string{}, ".", "/abs/path", "filename")
 	assert.Nil(t, err)
 	assert.Equal(t, out, expectOutput)
 }
+
+func TestFileContents_Labels(t *testing.T) {
+	testInputs := []struct {
+		name     string
+		metadata map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "labels and tags lists",
+			metadata: map[string]interface{}{
+				"labels": []interface{}{"Runbook", "on call"},
+				"tags":   []interface{}{"runbook", "Payments"},
+			},
+			expected: []string{"runbook", "on-call", "payments"},
+		},
+		{
+			name: "comma separated string",
+			metadata: map[string]interface{}{
+				"tags": "alpha, beta ,,",
+			},
+			expected: []string{"alpha", "beta"},
+		},
+		{
+			name:     "no labels",
+			metadata: map[string]interface{}{"title": "filename"},
+			expected: nil,
+		},
+	}
+
+	for _, test := range testInputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			contents := FileContents{MetaData: test.metadata}
+			assert.Equal(t, test.expected, contents.Labels())
+		})
+	}
+}
+
+func TestPageTitle(t *testing.T) {
+	testInputs := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "frontmatter title", content: "---\ntitle: \" Guide \"\n---\n# Heading\n", expected: "Guide"},
+		{name: "empty frontmatter title", content: "+++\ntitle = \"\"\n+++\n# Heading\n", expected: "Heading"},
+		{name: "first heading", content: "text\n## Second\n# First [link](a.md) `code` **bold** ##\n# Third\n", expected: "First link code bold"},
+		{name: "heading ending with #", content: "# C#\n", expected: "C#"},
+		{name: "heading in frontmatter and code", content: "---\n# yaml comment\n---\n```\n# code\n```\n    # indented\n#tag\n", expected: "file.md"},
+		{name: "no heading", content: "text\n", expected: "file.md"},
+	}
+
+	for _, test := range testInputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, PageTitle([]byte(test.content), "file.md"))
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	content := "see [the guide](docs/guide.md#setup) and ![diagram]( <images/flow.png> )\n" +
+		"[site](https://example.com) [anchor](#top)\n" +
+		`<a href="../other.md">other</a> <img SRC="logo.png" />` + "\n"
+
+	assert.Equal(t, []string{"docs/guide.md#setup", "images/flow.png", "https://example.com", "#top", "../other.md", "logo.png"},
+		Links([]byte(content)))
+	assert.Empty(t, Links([]byte("no links here\n")))
+}
@@ -0,0 +1,111 @@
+package markdown
+
+// title - the title of a page from the frontmatter or the first heading of the markdown
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gohugoio/hugo/parser/pageparser"
+)
+
+var markdownLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// FrontmatterTitle function returns the title set in the frontmatter - empty if there is none
+func FrontmatterTitle(metaData map[string]interface{}) string {
+	value, ok := metaData["title"]
+	if !ok || value == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+// PageTitle function returns the title of a markdown page: the title set in the frontmatter,
+// otherwise the first # heading of the markdown, otherwise the fallback (the file name)
+func PageTitle(content []byte, fallback string) string {
+	fmc, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(content))
+	if err == nil {
+		if title := FrontmatterTitle(fmc.FrontMatter); title != "" {
+			return title
+		}
+	}
+
+	if title := firstHeading(content); title != "" {
+		return title
+	}
+
+	return fallback
+}
+
+// firstHeading function returns the text of the first # heading of the markdown
+// ignoring the frontmatter and code blocks - empty if there is none
+func firstHeading(content []byte) string {
+	var (
+		fence       string
+		frontmatter string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		switch {
+		case first && (line == "---" || line == "+++"):
+			frontmatter = line
+			continue
+		case frontmatter != "":
+			if line == frontmatter {
+				frontmatter = ""
+			}
+
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			continue // an indented code block
+		}
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if trimmed == "#" || strings.HasPrefix(trimmed, "# ") || strings.HasPrefix(trimmed, "#\t") {
+			if heading := cleanHeading(trimmed[1:]); heading != "" {
+				return heading
+			}
+		}
+	}
+
+	return ""
+}
+
+// cleanHeading function returns the plain text of a heading - without closing #s, links or emphasis
+func cleanHeading(heading string) string {
+	heading = strings.TrimSpace(heading)
+
+	// a closing sequence of #s has to follow a space (so # C# keeps its #)
+	if closed := strings.TrimRight(heading, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		heading = strings.TrimSpace(closed)
+	}
+
+	heading = markdownLink.ReplaceAllString(heading, "$1")
+	heading = strings.NewReplacer("`", "", "**", "", "__", "", "*", "").Replace(heading)
+
+	return strings.Join(strings.Fields(heading), " ")
+}
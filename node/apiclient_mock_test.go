@@ -5,6 +5,7 @@
 package node
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,75 +36,192 @@ func (m *MockAPIClienter) EXPECT() *MockAPIClienterMockRecorder {
 	return m.recorder
 }
 
+// AddLabels mocks base method.
+func (m *MockAPIClienter) AddLabels(ctx context.Context, pageID int, labels []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLabels", ctx, pageID, labels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddLabels indicates an expected call of AddLabels.
+func (mr *MockAPIClienterMockRecorder) AddLabels(ctx, pageID, labels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLabels", reflect.TypeOf((*MockAPIClienter)(nil).AddLabels), ctx, pageID, labels)
+}
+
 // CreatePage mocks base method.
-func (m *MockAPIClienter) CreatePage(root int, contents *markdown.FileContents, isroot bool) (int, error) {
+func (m *MockAPIClienter) CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePage", root, contents, isroot)
+	ret := m.ctrl.Call(m, "CreatePage", ctx, root, contents, isroot)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreatePage indicates an expected call of CreatePage.
-func (mr *MockAPIClienterMockRecorder) CreatePage(root, contents, isroot interface{}) *gomock.Call {
+func (mr *MockAPIClienterMockRecorder) CreatePage(ctx, root, contents, isroot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePage", reflect.TypeOf((*MockAPIClienter)(nil).CreatePage), ctx, root, contents, isroot)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAPIClienter) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", ctx, attachmentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAPIClienterMockRecorder) DeleteAttachment(ctx, attachmentID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePage", reflect.TypeOf((*MockAPIClienter)(nil).CreatePage), root, contents, isroot)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAPIClienter)(nil).DeleteAttachment), ctx, attachmentID)
 }
 
 // DeletePage mocks base method.
-func (m *MockAPIClienter) DeletePage(pageID int) error {
+func (m *MockAPIClienter) DeletePage(ctx context.Context, pageID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePage", pageID)
+	ret := m.ctrl.Call(m, "DeletePage", ctx, pageID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePage indicates an expected call of DeletePage.
-func (mr *MockAPIClienterMockRecorder) DeletePage(pageID interface{}) *gomock.Call {
+func (mr *MockAPIClienterMockRecorder) DeletePage(ctx, pageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePage", reflect.TypeOf((*MockAPIClienter)(nil).DeletePage), ctx, pageID)
+}
+
+// FindManagedPages mocks base method.
+func (m *MockAPIClienter) FindManagedPages(ctx context.Context, pageID int) ([]confluence.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindManagedPages", ctx, pageID)
+	ret0, _ := ret[0].([]confluence.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindManagedPages indicates an expected call of FindManagedPages.
+func (mr *MockAPIClienterMockRecorder) FindManagedPages(ctx, pageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePage", reflect.TypeOf((*MockAPIClienter)(nil).DeletePage), pageID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindManagedPages", reflect.TypeOf((*MockAPIClienter)(nil).FindManagedPages), ctx, pageID)
 }
 
 // FindPage mocks base method.
-func (m *MockAPIClienter) FindPage(title string, many bool) (*confluence.PageResults, error) {
+func (m *MockAPIClienter) FindPage(ctx context.Context, title string, many bool) (*confluence.PageResults, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindPage", title, many)
+	ret := m.ctrl.Call(m, "FindPage", ctx, title, many)
 	ret0, _ := ret[0].(*confluence.PageResults)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindPage indicates an expected call of FindPage.
-func (mr *MockAPIClienterMockRecorder) FindPage(title, many interface{}) *gomock.Call {
+func (mr *MockAPIClienterMockRecorder) FindPage(ctx, title, many interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPage", reflect.TypeOf((*MockAPIClienter)(nil).FindPage), ctx, title, many)
+}
+
+// GetLabels mocks base method.
+func (m *MockAPIClienter) GetLabels(ctx context.Context, pageID int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLabels", ctx, pageID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLabels indicates an expected call of GetLabels.
+func (mr *MockAPIClienterMockRecorder) GetLabels(ctx, pageID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPage", reflect.TypeOf((*MockAPIClienter)(nil).FindPage), title, many)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLabels", reflect.TypeOf((*MockAPIClienter)(nil).GetLabels), ctx, pageID)
+}
+
+// GetProperty mocks base method.
+func (m *MockAPIClienter) GetProperty(ctx context.Context, pageID int, key string) (*confluence.Property, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProperty", ctx, pageID, key)
+	ret0, _ := ret[0].(*confluence.Property)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProperty indicates an expected call of GetProperty.
+func (mr *MockAPIClienterMockRecorder) GetProperty(ctx, pageID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProperty", reflect.TypeOf((*MockAPIClienter)(nil).GetProperty), ctx, pageID, key)
+}
+
+// ListAttachments mocks base method.
+func (m *MockAPIClienter) ListAttachments(ctx context.Context, pageID int) ([]confluence.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAttachments", ctx, pageID)
+	ret0, _ := ret[0].([]confluence.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAttachments indicates an expected call of ListAttachments.
+func (mr *MockAPIClienterMockRecorder) ListAttachments(ctx, pageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAttachments", reflect.TypeOf((*MockAPIClienter)(nil).ListAttachments), ctx, pageID)
+}
+
+// RemoveLabel mocks base method.
+func (m *MockAPIClienter) RemoveLabel(ctx context.Context, pageID int, label string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveLabel", ctx, pageID, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveLabel indicates an expected call of RemoveLabel.
+func (mr *MockAPIClienterMockRecorder) RemoveLabel(ctx, pageID, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveLabel", reflect.TypeOf((*MockAPIClienter)(nil).RemoveLabel), ctx, pageID, label)
+}
+
+// SetProperty mocks base method.
+func (m *MockAPIClienter) SetProperty(ctx context.Context, pageID int, key string, value interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProperty", ctx, pageID, key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProperty indicates an expected call of SetProperty.
+func (mr *MockAPIClienterMockRecorder) SetProperty(ctx, pageID, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProperty", reflect.TypeOf((*MockAPIClienter)(nil).SetProperty), ctx, pageID, key, value)
 }
 
 // UpdatePage mocks base method.
-func (m *MockAPIClienter) UpdatePage(pageID int, pageVersion int64, pageContents *markdown.FileContents, originalPage confluence.PageResults) (bool, error) {
+func (m *MockAPIClienter) UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents, originalPage confluence.PageResults) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePage", pageID, pageVersion, pageContents, originalPage)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "UpdatePage", ctx, pageID, parentID, pageVersion, pageContents, originalPage)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdatePage indicates an expected call of UpdatePage.
-func (mr *MockAPIClienterMockRecorder) UpdatePage(pageID, pageVersion, pageContents, originalPage interface{}) *gomock.Call {
+func (mr *MockAPIClienterMockRecorder) UpdatePage(ctx, pageID, parentID, pageVersion, pageContents, originalPage interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePage", reflect.TypeOf((*MockAPIClienter)(nil).UpdatePage), pageID, pageVersion, pageContents, originalPage)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePage", reflect.TypeOf((*MockAPIClienter)(nil).UpdatePage), ctx, pageID, parentID, pageVersion, pageContents, originalPage)
 }
 
 // UploadAttachment mocks base method.
-func (m *MockAPIClienter) UploadAttachment(filename string, id int, index bool, indexid int) error {
+func (m *MockAPIClienter) UploadAttachment(ctx context.Context, filename string, id int, index bool, indexid int) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UploadAttachment", filename, id, index, indexid)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UploadAttachment", ctx, filename, id, index, indexid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UploadAttachment indicates an expected call of UploadAttachment.
-func (mr *MockAPIClienterMockRecorder) UploadAttachment(filename, id, index, indexid interface{}) *gomock.Call {
+func (mr *MockAPIClienterMockRecorder) UploadAttachment(ctx, filename, id, index, indexid interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockAPIClienter)(nil).UploadAttachment), filename, id, index, indexid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockAPIClienter)(nil).UploadAttachment), ctx, filename, id, index, indexid)
 }
@@ -3,6 +3,7 @@ package node
 // check - methods for checking various conditions
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -20,8 +21,7 @@ import (
 // then it calls generateMaster method on subnode
 func (node *Node) checkIfRootAlive(fpath string) {
 	if node.path != fpath {
-		subNode := newNode()
-		subNode.treeLink = node.treeLink
+		subNode := newNode(node.syncer)
 		subNode.path = fpath
 		subNode.images = node.images
 
@@ -77,39 +77,30 @@ func (node *Node) checkIfMarkDown(fpath string, checking bool) bool {
 	return false
 }
 
-// hasValidSuffix method checks if file has one of the valid suffixes provided
-func hasValidSuffix(name string, validSuffixes []string) bool {
-	for index := range validSuffixes {
-		if strings.HasSuffix(strings.ToLower(name), validSuffixes[index]) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // checkIfProcessableFile method checks whether file is a file we can process or not
+// (a page file type the repo config publishes)
 // checking bool is for whether we are just checking returning bool, or
 // if we are doing work on file
 func (node *Node) checkIfProcessableFile(checking bool, name string) bool {
 	fileName := filepath.Base(name)
-	validSuffixes := []string{".md", ".swagger.json"}
 
-	if hasValidSuffix(name, validSuffixes) {
+	if node.syncer.repoConfig.Page(name) {
 		if !checking {
 			if strings.ToLower(fileName) == indexName { // we don't want to process index.md here
 				return true
 			}
 
-			err := node.processFilesDown(name, fileName)
-			if err != nil {
-				log.Println(err)
+			if node.syncer.stopping(name) {
+				return true
 			}
 
+			err := node.processFilesDown(name, fileName)
+			node.syncer.fileError(name, err)
+
 			return true
 		}
 
-		foldersWithMarkdown++
+		node.syncer.foldersWithMarkdown++
 
 		return true
 	}
@@ -118,12 +109,15 @@ func (node *Node) checkIfProcessableFile(checking bool, name string) bool {
 }
 
 // checkIfFolder method checks filepath is a folder or not
-// and returns bool
-func (node *Node) checkIfFolder(fpath string) bool {
-	if isFolder(fpath) && !isVendorOrGit(fpath) {
-		numberOfFolders++
+// and returns bool - the pages of the folder are only generated when processing
+// (once the page of the folder above has been generated) so each of them is generated once
+func (node *Node) checkIfFolder(fpath string, checking bool) bool {
+	if isFolder(fpath) && node.syncer.repoConfig.Folder(fpath) {
+		if !checking {
+			node.syncer.numberOfFolders++
 
-		node.checkIfRootAlive(fpath)
+			node.checkIfRootAlive(fpath)
+		}
 
 		return true
 	}
@@ -134,7 +128,7 @@ func (node *Node) checkIfFolder(fpath string) bool {
 // checkOtherFileTypes method checks if file is a folder
 // and if not, checks for if it is a go or image file
 func (node *Node) checkOtherFileTypes(fpath string, checking bool) bool {
-	if !node.checkIfFolder(fpath) {
+	if !node.checkIfFolder(fpath, checking) {
 		node.checkIfGoFile(fpath)
 		return node.checkForImages(fpath, checking)
 	}
@@ -155,29 +149,25 @@ func (node *Node) checkIfGoFile(name string) {
 	}
 }
 
-// checkForImages method checks to see if the file is an image file
+// checkForImages method checks to see if the file is an image file the repo config publishes
 func (node *Node) checkForImages(name string, checking bool) bool {
-	validFiles := []string{".png", ".jpg", ".jpeg", ".gif"}
-
-	for index := range validFiles {
-		if strings.HasSuffix(strings.ToLower(name), validFiles[index]) {
-			if checking {
-				node.alive = true
-			} else {
-				node.checkNodeRootIsNil(name)
-			}
+	if !node.syncer.repoConfig.Image(name) {
+		return false
+	}
 
-			return true
-		}
+	if checking {
+		node.alive = true
+	} else {
+		node.checkNodeRootIsNil(name)
 	}
 
-	return false
+	return true
 }
 
 // checkNodeRootIsNil method checks whether the
 // node root is nil before calling uploadFile method
 func (node *Node) checkNodeRootIsNil(name string) {
-	if node.root != nil {
+	if node.root != nil && !node.syncer.stopping(name) {
 		if node.imageToBeUploaded(name) {
 			node.uploadFile(name, node.indexPage)
 		}
@@ -196,6 +186,9 @@ func (node *Node) imageToBeUploaded(name string) bool {
 }
 
 // checkConfluencePages method runs through the CRUD operations for confluence
+// pages are found by title, or failing that by the managed property stored on pages
+// under the parent page, or by the file's path before it was renamed
+// and pages managed for a different file are never overwritten
 func (node *Node) checkConfluencePages(newPageContents *markdown.FileContents, filepath string) error {
 	_, abs := node.generateTitles()
 
@@ -204,47 +197,83 @@ func (node *Node) checkConfluencePages(newPageContents *markdown.FileContents, f
 			abs)
 	}
 
-	pageTitle := strings.Join(strings.Split(newPageContents.MetaData["title"].(string), " "), "+")
+	pageTitle := newPageContents.MetaData["title"].(string)
+
+	managed := node.syncer.newManagedProperty(newPageContents, filepath)
 
-	pageResult, err := nodeAPIClient.FindPage(pageTitle, false)
+	pageResult, err := node.syncer.client.FindPage(node.syncer.requestCtx, pageTitle, false)
 	if err != nil {
 		return fmt.Errorf("find page error for folder path [%s] - page title [%s]: %w",
 			abs, pageTitle, err)
 	}
 
 	if pageResult == nil {
-		err := node.newPage(newPageContents)
+		pageResult, err = node.findManagedPage(managed)
 		if err != nil {
-			return fmt.Errorf("create page error for folder path [%s] - page title [%s]: %w",
+			return fmt.Errorf("find managed page error for folder path [%s] - page title [%s]: %w",
 				abs, pageTitle, err)
 		}
+	}
 
-		mapSem <- struct{}{}
+	if pageResult == nil {
+		pageResult, err = node.syncer.findRenamedPage(managed)
+		if err != nil {
+			return fmt.Errorf("find renamed page error for folder path [%s] - page title [%s]: %w",
+				abs, pageTitle, err)
+		}
+	}
+
+	version, result := 1, ResultCreated // the version of the page once it is created or updated
 
-		id := strconv.Itoa(node.id)
+	if pageResult == nil {
+		err = node.newPage(newPageContents)
+		if err != nil {
+			return fmt.Errorf("create page error for folder path [%s] - page title [%s]: %w",
+				abs, pageTitle, err)
+		}
+	} else {
+		err = node.syncer.checkOwnership(pageResult.Results[0], managed, node.parentID())
+		if err != nil {
+			return fmt.Errorf("ownership error for folder path [%s] - page title [%s]: %w",
+				abs, pageTitle, err)
+		}
 
-		node.treeLink.branches[filepath] = id
+		version, result, err = node.createOrUpdatePage(newPageContents, pageResult, managed)
+		if err != nil {
+			return fmt.Errorf("create/update page error for folder path [%s] - page title [%s]: %w",
+				abs, pageTitle, err)
+		}
+	}
 
-		log.Printf("processed file - id: [%d]", node.id)
-		<-mapSem
+	managed.Version = version
+	managed.Labels = node.syncer.wantedLabels(newPageContents, filepath)
 
-		return nil
+	err = node.markManaged(pageResult, managed)
+	if err != nil {
+		log.Printf("managed property error for folder path [%s] - page title [%s]: %v", abs, pageTitle, err)
 	}
 
-	err = node.createOrUpdatePage(newPageContents, pageResult)
+	err = node.syncLabels(managed.Labels, appliedLabels(pageResult))
 	if err != nil {
-		return fmt.Errorf("create/update page error for folder path [%s] - page title [%s]: %w",
-			abs, pageTitle, err)
+		log.Printf("label error for folder path [%s] - page title [%s]: %v", abs, pageTitle, err)
 	}
 
-	mapSem <- struct{}{}
+	node.syncer.tree.mapSem <- struct{}{}
 
 	id := strconv.Itoa(node.id)
 
-	node.treeLink.branches[filepath] = id
+	if planned, ok := node.syncer.tree.branches[filepath]; ok && planned != id {
+		log.Printf("the page of [%s] is [%s] not [%s] - links to it are corrected on the next run", filepath, id, planned)
+	}
+
+	node.syncer.tree.branches[filepath] = id
+	node.syncer.tree.pages[node.syncer.managedPath(filepath)] = confluence.ManifestPage{ID: node.id, Version: version, Hash: managed.Hash,
+		Title: newPageContents.MetaData["title"].(string), ParentID: node.parentID()}
 
 	log.Printf("processed file - id: [%d]", node.id)
-	<-mapSem
+	<-node.syncer.tree.mapSem
+
+	node.syncer.recordPage(filepath, result, newPageContents.MetaData["title"].(string), node.id)
 
 	return nil
 }
@@ -267,38 +296,69 @@ func (node *Node) newPage(newPageContents *markdown.FileContents) error {
 	return nil
 }
 
+// createOrUpdatePage method updates the page found for the file (or creates it again if it was removed since)
+// and returns the version of the page once it is updated and what was done with it (see Report)
+// a page that is unchanged (as the node or the client finds it) is not written again so it keeps its version
 func (node *Node) createOrUpdatePage(newPageContents *markdown.FileContents,
-	pageResult *confluence.PageResults) error {
+	pageResult *confluence.PageResults, managed confluence.ManagedProperty) (int, string, error) {
 	_, abs := node.generateTitles()
 
 	if newPageContents == nil {
-		return fmt.Errorf("createOrUpdatePage error for folder path [%s]: the newPageContents param was nil",
+		return 0, "", fmt.Errorf("createOrUpdatePage error for folder path [%s]: the newPageContents param was nil",
 			abs)
 	}
 
 	if pageResult == nil {
-		return fmt.Errorf("createOrUpdatePage pageResult error for folder path [%s]: the pageResult param was nil",
+		return 0, "", fmt.Errorf("createOrUpdatePage pageResult error for folder path [%s]: the pageResult param was nil",
 			abs)
 	}
 
 	err := node.checkPageID(*pageResult)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 
-	if len(pageResult.Results) > 0 {
-		addToList, err := nodeAPIClient.UpdatePage(node.id, int64(pageResult.Results[0].Version.Number),
-			newPageContents, *pageResult)
-		if err != nil {
-			return err
-		}
+	if len(pageResult.Results) == 0 {
+		return 0, ResultUnchanged, nil
+	}
 
-		if addToList {
-			node.addContents(newPageContents)
-		}
+	current := pageResult.Results[0]
+
+	if node.pageUnchanged(current, newPageContents, managed) {
+		log.Printf("page [%d] is unchanged - it is not written again", node.id)
+
+		node.addContents(newPageContents)
+
+		return current.Version.Number, ResultUnchanged, nil
 	}
 
-	return nil
+	version, err := node.syncer.client.UpdatePage(node.syncer.requestCtx, node.id, node.parentID(), int64(current.Version.Number),
+		newPageContents, *pageResult)
+	if errors.Is(err, confluence.ErrNotFound) { // the page was removed since it was found
+		log.Printf("page [%d] no longer exists - creating it again", node.id)
+
+		return 1, ResultCreated, node.newPage(newPageContents)
+	}
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	node.addContents(newPageContents)
+
+	if version == 0 { // the client found the page unchanged so it was not written
+		return current.Version.Number, ResultUnchanged, nil
+	}
+
+	if parentID := node.parentID(); parentID != 0 && current.ParentID() != parentID {
+		return version, ResultMoved, nil
+	}
+
+	if found, ok := current.Managed(); ok && found.Path != managed.Path { // the file was renamed
+		return version, ResultMoved, nil
+	}
+
+	return version, ResultUpdated, nil
 }
 
 // addContents adds the page title to either the parent page titles slice, or the node slice
@@ -0,0 +1,42 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// TestCreateOrUpdatePage checks a page the client found unchanged (so it did not write it) keeps its version
+// and is reported as unchanged - and a page the client wrote is given the version the client returned
+func TestCreateOrUpdatePage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := NewMockAPIClienter(mockCtrl)
+
+	found := &confluence.PageResults{Results: []confluence.Page{{ID: "42", Title: "page",
+		Version: confluence.VersionObj{Number: 4}, Ancestors: []confluence.AncestorObj{{ID: 7}},
+		Body: confluence.BodyObj{Storage: confluence.StorageObj{Value: "<p>old</p>"}}}}}
+
+	contents := &markdown.FileContents{MetaData: map[string]interface{}{"title": "page"}, Body: []byte("<p>new</p>")}
+
+	node := newNode(NewSyncer(Options{}, mock))
+	node.masterID = 7
+
+	mock.EXPECT().UpdatePage(gomock.Any(), 42, 7, int64(4), contents, *found).Return(0, nil)
+
+	version, result, err := node.createOrUpdatePage(contents, found, confluence.ManagedProperty{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
+	assert.Equal(t, ResultUnchanged, result)
+
+	mock.EXPECT().UpdatePage(gomock.Any(), 42, 7, int64(4), contents, *found).Return(5, nil)
+
+	version, result, err = node.createOrUpdatePage(contents, found, confluence.ManagedProperty{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, version)
+	assert.Equal(t, ResultUpdated, result)
+}
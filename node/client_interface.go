@@ -1,6 +1,7 @@
 package node
 
 import (
+	"context"
 	"github.com/xiatechs/markdown-to-confluence/confluence"
 	"github.com/xiatechs/markdown-to-confluence/markdown"
 )
@@ -9,10 +10,18 @@ import (
 
 // APIClienter is interface for confluence API client and mock tests
 type APIClienter interface {
-	CreatePage(root int, contents *markdown.FileContents, isroot bool) (int, error)
-	DeletePage(pageID int) error
-	UpdatePage(pageID int, pageVersion int64, pageContents *markdown.FileContents,
-		originalPage confluence.PageResults) (bool, error)
-	FindPage(title string, many bool) (*confluence.PageResults, error)
-	UploadAttachment(filename string, id int, index bool, indexid int) error
+	CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error)
+	DeletePage(ctx context.Context, pageID int) error
+	UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents,
+		originalPage confluence.PageResults) (int, error)
+	FindPage(ctx context.Context, title string, many bool) (*confluence.PageResults, error)
+	FindManagedPages(ctx context.Context, pageID int) ([]confluence.Page, error)
+	UploadAttachment(ctx context.Context, filename string, id int, index bool, indexid int) (bool, error)
+	GetLabels(ctx context.Context, pageID int) ([]string, error)
+	AddLabels(ctx context.Context, pageID int, labels []string) error
+	RemoveLabel(ctx context.Context, pageID int, label string) error
+	GetProperty(ctx context.Context, pageID int, key string) (*confluence.Property, error)
+	SetProperty(ctx context.Context, pageID int, key string, value interface{}) error
+	ListAttachments(ctx context.Context, pageID int) ([]confluence.Attachment, error)
+	DeleteAttachment(ctx context.Context, attachmentID string) error
 }
@@ -0,0 +1,31 @@
+package node
+
+// context - stopping a run part way: the run context stops new files and folders being started
+// while confluence requests use the request context, so work already started can finish
+
+// stopping method returns true if the run has been stopped, recording path as left undone
+func (syncer *Syncer) stopping(path string) bool {
+	if syncer.runCtx.Err() == nil {
+		return false
+	}
+
+	syncer.skip(path)
+
+	return true
+}
+
+// skip method records a file or folder that was not synced because the run was stopped
+func (syncer *Syncer) skip(path string) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	syncer.skipped = append(syncer.skipped, path)
+}
+
+// Skipped method returns the files and folders that were not synced because the run was stopped
+func (syncer *Syncer) Skipped() []string {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	return append([]string(nil), syncer.skipped...)
+}
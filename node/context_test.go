@@ -0,0 +1,22 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	syncer := NewSyncer(Options{}, nil)
+	syncer.runCtx = ctx
+
+	assert.False(t, syncer.stopping("docs/a.md"))
+
+	cancel()
+
+	assert.True(t, syncer.stopping("docs/b.md"))
+	assert.Equal(t, []string{"docs/b.md"}, syncer.Skipped())
+}
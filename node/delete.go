@@ -3,6 +3,8 @@ package node
 // delete - methods regarding deleting pages in confluence wiki
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"strconv"
 
@@ -13,8 +15,8 @@ import (
 func (node *Node) findPagesToDelete(id string) {
 	findParentPageAndChildren := true
 
-	if nodeAPIClient != nil {
-		children, err := nodeAPIClient.FindPage(id, findParentPageAndChildren)
+	if node.syncer.client != nil {
+		children, err := node.syncer.client.FindPage(node.syncer.requestCtx, id, findParentPageAndChildren)
 		if err != nil {
 			log.Printf("error finding page: %s", err)
 		}
@@ -27,6 +29,9 @@ func (node *Node) findPagesToDelete(id string) {
 
 // deletePages method is to find a page to delete
 // and any children pages that might need to be deleted
+// only pages marked as managed by the tool for this repo are deleted - and never a page synced from a file this run
+// (found by its page ID or the file path in its managed property, as its title in confluence can be the title
+// it had before the file was retitled or moved when the changes were only planned)
 func (node *Node) deletePages(children *confluence.PageResults) {
 	for index := range children.Results {
 		var noDelete bool
@@ -38,26 +43,204 @@ func (node *Node) deletePages(children *confluence.PageResults) {
 			}
 		}
 
-		if !noDelete {
-			node.findPagesToDelete(children.Results[index].ID)
+		if noDelete || node.syncer.tree.synced(children.Results[index]) {
+			continue
+		}
+
+		if !node.syncer.isManaged(children.Results[index]) {
+			log.Printf("page [%s] with title [%s] was not created by this tool for [%s] - refusing to delete it",
+				children.Results[index].ID, children.Results[index].Title, node.syncer.repoName())
+
+			continue
+		}
+
+		if node.syncer.fileFailed(children.Results[index]) {
+			log.Printf("page [%s] with title [%s] is kept as its file failed to sync this run",
+				children.Results[index].ID, children.Results[index].Title)
 
-			go node.deletePage(children.Results[index].ID)
+			continue
 		}
+
+		node.findPagesToDelete(children.Results[index].ID)
+
+		page := children.Results[index]
+
+		node.syncer.wg.Go(node.syncer.requestCtx, func() {
+			node.deletePage(page)
+		}, func() {
+			err := fmt.Errorf("page [%s] was not removed: %w", page.ID, node.syncer.requestCtx.Err())
+
+			node.syncer.addDeleteError(err)
+			node.syncer.recordRemoval(page, err)
+		})
 	}
 }
 
-// deletePage method converts id to integer to pass to the API method DeletePage
-// this method can be run concurrently with no wait needed:
-// the pages are deleted by ID and don't need parent page reference
-func (node *Node) deletePage(id string) {
-	convert, err := strconv.Atoi(id)
+// deletePage method converts the page id to integer to pass to the API method DeletePage
+// this method can be run concurrently: the pages are deleted by ID and don't need
+// parent page reference - any failure is recorded and reported by deleteResult (and in the Report)
+func (node *Node) deletePage(page confluence.Page) {
+	convert, err := strconv.Atoi(page.ID)
+	if err != nil {
+		err = fmt.Errorf("error getting page ID [%s]: %w", page.ID, err)
+
+		node.syncer.addDeleteError(err)
+		node.syncer.recordRemoval(page, err)
+
+		return
+	}
+
+	err = node.syncer.client.DeletePage(node.syncer.requestCtx, convert)
+	if errors.Is(err, confluence.ErrNotFound) {
+		log.Printf("page [%d] was already removed", convert)
+		return
+	}
+
 	if err != nil {
-		log.Printf("error getting page ID: %s", err)
+		err = fmt.Errorf("error deleting page [%d]: %w", convert, err)
+
+		node.syncer.addDeleteError(err)
+		node.syncer.recordRemoval(page, err)
+
 		return
 	}
 
-	err = nodeAPIClient.DeletePage(convert)
+	node.syncer.recordRemoval(page, nil)
+
+	log.Printf("removed page [%d]", convert)
+}
+
+// addDeleteError method records a page that could not be removed
+func (syncer *Syncer) addDeleteError(err error) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	syncer.deleteErrors = append(syncer.deleteErrors, err)
+}
+
+// deleteResult method logs every page that could not be removed this run
+// and returns an error wrapping the first failure if there were any
+func (syncer *Syncer) deleteResult() error {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	if len(syncer.deleteErrors) == 0 {
+		return nil
+	}
+
+	for index := range syncer.deleteErrors {
+		log.Println(syncer.deleteErrors[index])
+	}
+
+	err := fmt.Errorf("failed to remove %d page(s) - first error: %w", len(syncer.deleteErrors), syncer.deleteErrors[0])
+
+	syncer.deleteErrors = nil
+
+	return err
+}
+
+// addAttachment method records an attachment uploaded to a page this run
+func (tree *Tree) addAttachment(pageID int, fileName string) {
+	tree.mapSem <- struct{}{}
+
+	defer func() { <-tree.mapSem }()
+
+	if tree.attachments[pageID] == nil {
+		tree.attachments[pageID] = map[string]bool{}
+	}
+
+	tree.attachments[pageID][fileName] = true
+}
+
+// setBranch method records the page ID links to the path are resolved with
+func (tree *Tree) setBranch(path string, id int) {
+	tree.mapSem <- struct{}{}
+
+	defer func() { <-tree.mapSem }()
+
+	tree.branches[path] = strconv.Itoa(id)
+}
+
+// synced method returns true if the page was synced from a file this run - the page has the ID of a page synced
+// this run or is managed for the path of a file synced this run
+func (tree *Tree) synced(page confluence.Page) bool {
+	tree.mapSem <- struct{}{}
+
+	defer func() { <-tree.mapSem }()
+
+	if managed, ok := page.Managed(); ok {
+		if _, found := tree.pages[managed.Path]; found {
+			return true
+		}
+	}
+
+	id, err := strconv.Atoi(page.ID)
 	if err != nil {
-		log.Printf("error deleting page: %s", err)
+		return false
+	}
+
+	for _, synced := range tree.pages {
+		if synced.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// managedPageIDs method returns the IDs of every page created or updated this run
+// (pages that were only planned have negative IDs and are left out - as are placeholder pages never rendered)
+func (tree *Tree) managedPageIDs() []int {
+	tree.mapSem <- struct{}{}
+
+	defer func() { <-tree.mapSem }()
+
+	seen := map[int]bool{}
+
+	var ids []int
+
+	for _, page := range tree.pages {
+		if page.ID <= 0 || seen[page.ID] {
+			continue
+		}
+
+		seen[page.ID] = true
+
+		ids = append(ids, page.ID)
+	}
+
+	return ids
+}
+
+// deleteOrphanedAttachments method lists the attachments on every page managed this run
+// and deletes those uploaded by the tool that were not uploaded this run
+// (i.e. the file has been removed or renamed in the repo)
+// attachments added by hand (without the tool's sha256 comment) are never deleted
+func (node *Node) deleteOrphanedAttachments() {
+	if node.syncer.client == nil || node.syncer.tree == nil {
+		return
+	}
+
+	for _, pageID := range node.syncer.tree.managedPageIDs() {
+		attachments, err := node.syncer.client.ListAttachments(node.syncer.requestCtx, pageID)
+		if err != nil {
+			log.Printf("error listing attachments for page [%d]: %s", pageID, err)
+			continue
+		}
+
+		kept := node.syncer.tree.attachments[pageID]
+
+		for index := range attachments {
+			if attachments[index].Hash() == "" || kept[attachments[index].Title] {
+				continue
+			}
+
+			log.Printf("deleting orphaned attachment [%s] from page [%d]", attachments[index].Title, pageID)
+
+			err = node.syncer.client.DeleteAttachment(node.syncer.requestCtx, attachments[index].ID)
+			if err != nil {
+				log.Printf("error deleting attachment [%s]: %s", attachments[index].Title, err)
+			}
+		}
 	}
 }
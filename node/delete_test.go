@@ -0,0 +1,34 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+func TestDeleteOrphanedAttachments(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := NewMockAPIClienter(mockCtrl)
+
+	tool := confluence.AttachmentMetadata{Comment: "file uploaded using markdown-github-action sha256:abc"}
+
+	mock.EXPECT().ListAttachments(gomock.Any(), 5).Return([]confluence.Attachment{
+		{ID: "1", Title: "kept.png", Metadata: tool},
+		{ID: "2", Title: "removed.png", Metadata: tool},
+		{ID: "3", Title: "added-by-hand.png"},
+	}, nil)
+	mock.EXPECT().DeleteAttachment(gomock.Any(), "2").Return(nil)
+
+	node := newNode(NewSyncer(Options{}, mock))
+	node.syncer.tree = &Tree{
+		mapSem:      make(chan struct{}, 1),
+		branches:    map[string]string{"docs": "5", "docs/readme.md": "5"},
+		attachments: map[int]map[string]bool{5: {"kept.png": true}},
+		pages:       map[string]confluence.ManifestPage{"docs": {ID: 5}},
+	}
+
+	node.deleteOrphanedAttachments()
+}
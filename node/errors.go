@@ -0,0 +1,72 @@
+package node
+
+// errors - reacting to the kind of error confluence returned
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+// abort method stops the run (no new files or folders are started)
+// because of an error that means no other page can be synced
+func (syncer *Syncer) abort(err error) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	if syncer.abortErr == nil {
+		syncer.abortErr = err
+
+		log.Printf("aborting the run: %v", err)
+	}
+
+	syncer.abortRun()
+}
+
+// Aborted method returns the error the run was aborted for, or nil if it was not aborted
+func (syncer *Syncer) Aborted() error {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	return syncer.abortErr
+}
+
+// handleError method logs an error from syncing a file with a hint for the kind of error
+// and aborts the run if confluence rejected the credentials - as every other request would fail too
+func (syncer *Syncer) handleError(err error) {
+	if err == nil {
+		return
+	}
+
+	var hint string
+
+	switch {
+	case errors.Is(err, confluence.ErrUnauthorized):
+		syncer.abort(fmt.Errorf("confluence rejected the credentials - check the key, username and authMethod: %w", err))
+
+		return
+	case errors.Is(err, confluence.ErrTitleExists):
+		hint = "another page in the space already has this title - change the title of the file or rename the other page"
+	case errors.Is(err, confluence.ErrForbidden):
+		hint = "the account does not have permission to edit this page or its parent page"
+	case errors.Is(err, confluence.ErrNotFound):
+		hint = "the page or its parent page no longer exists"
+	case errors.Is(err, confluence.ErrPayloadTooLarge):
+		hint = "the page or attachment is larger than confluence allows"
+	case errors.Is(err, confluence.ErrInvalidStorageFormat):
+		hint = "confluence could not parse the page generated from the file"
+	case errors.Is(err, confluence.ErrRateLimited):
+		hint = "confluence kept rate limiting the request"
+	case errors.Is(err, confluence.ErrConflict):
+		hint = "the page kept being edited while it was being updated"
+	}
+
+	if hint == "" {
+		log.Println(err)
+		return
+	}
+
+	log.Printf("%v (%s)", err, hint)
+}
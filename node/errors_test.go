@@ -0,0 +1,53 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+func TestHandleError(t *testing.T) {
+	inputs := []struct {
+		name          string
+		err           error
+		expectedAbort bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "title exists is only logged",
+			err:  fmt.Errorf("create page error: %w", confluence.ErrTitleExists),
+		},
+		{
+			name: "not found is only logged",
+			err:  fmt.Errorf("update page error: %w", confluence.ErrNotFound),
+		},
+		{
+			name:          "rejected credentials abort the run",
+			err:           fmt.Errorf("find page error: %w", confluence.ErrUnauthorized),
+			expectedAbort: true,
+		},
+	}
+
+	for _, test := range inputs {
+		syncer := NewSyncer(Options{}, nil)
+		syncer.runCtx, syncer.abortRun = context.WithCancel(context.Background())
+
+		syncer.handleError(test.err)
+
+		if !test.expectedAbort {
+			assert.NoError(t, syncer.Aborted(), test.name)
+			assert.NoError(t, syncer.runCtx.Err(), test.name)
+
+			continue
+		}
+
+		assert.True(t, errors.Is(syncer.Aborted(), confluence.ErrUnauthorized), test.name)
+		assert.Error(t, syncer.runCtx.Err(), test.name)
+	}
+}
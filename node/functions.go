@@ -10,9 +10,9 @@ import (
 	"sync"
 )
 
-// newNode function creates a new node object
-func newNode() *Node {
-	node := Node{}
+// newNode function creates a new node object for the sync
+func newNode(syncer *Syncer) *Node {
+	node := Node{syncer: syncer}
 
 	node.mu = &sync.RWMutex{}
 
@@ -24,14 +24,9 @@ func (node *Node) withinDirectory(base, path string) bool {
 	return strings.Count(path, "/")-strings.Count(base, "/") == 1
 }
 
-// isVendorOrGit function takes in name of folder and
-// checks if it is a vendor or github folder
-func isVendorOrGit(name string) bool {
-	if strings.Contains(name, "vendor") || strings.Contains(name, ".github") || strings.Contains(name, ".git") {
-		return true
-	}
-
-	return false
+// isSwagger function checks whether a file is a swagger file (rendered from swagger rather than markdown)
+func isSwagger(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".swagger.json")
 }
 
 // isFolder function checks whether a file is a folder or not
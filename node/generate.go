@@ -14,26 +14,25 @@ import (
 	"strings"
 
 	goplantuml "github.com/jfeliu007/goplantuml/parser"
-	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/config"
 	"github.com/xiatechs/markdown-to-confluence/markdown"
 )
 
-// generateMaster method checks if the OnlyDocs flag is true and if so then checks
-// if the folder is in the /repo/docs folder,
+// generateMaster method checks the repo config publishes the folder (e.g. with OnlyDocs
+// that the folder is in a docs folder and that it is not excluded),
 // whether the folder is alive (has markdown files in it) and if it is, creates a page for the folder.
 // Also, then checks whether the folder has subfolders in it,
 // and then begins the process of checking those folders (recursively)
+// nothing is generated once the run has been stopped
 func (node *Node) generateMaster() {
-	if common.OnlyDocs {
-		listOfFolders := strings.Split(node.path, "/")
+	if node.syncer.stopping(node.path) {
+		return
+	}
 
-		// we only want to read from the repo/docs folder
-		// so if there is at least 1 sub folder and it is not /docs ignore
-		if len(listOfFolders) > 1 && !strings.Contains(node.path, "/docs") {
-			log.Printf("skipping this folder [%s] because not in the /docs folder", node.path)
+	if !node.syncer.repoConfig.Folder(node.path) {
+		log.Printf("skipping this folder [%s] because the repo config does not publish it", node.path)
 
-			return
-		}
+		return
 	}
 
 	// these constants are to aid navigation of iterate method lower down
@@ -44,10 +43,9 @@ func (node *Node) generateMaster() {
 		files      = false
 	)
 
-	subNode := newNode()
+	subNode := newNode(node.syncer)
 	subNode.path = node.path
 	subNode.root = node
-	subNode.treeLink = node.treeLink
 	subNode.images = node.images
 	node.branches = append(node.branches, subNode)
 
@@ -57,6 +55,15 @@ func (node *Node) generateMaster() {
 	if !thereAreValidMDFiles && !thereAreValidImageFiles {
 		log.Printf("no valid files here [%s]", node.path)
 
+		if node.root == nil { // the root folder always has a page
+			err := node.generateFolderPage(false)
+			if err != nil {
+				node.syncer.fileError(node.path, fmt.Errorf("generate folder page error: %w", err))
+			}
+		}
+
+		subNode.iterate(processing, folders) // the sub folders are made under the page of the folder above
+
 		return
 	}
 
@@ -64,7 +71,7 @@ func (node *Node) generateMaster() {
 
 	err := node.generateFolderPage(subNode.hasIndex)
 	if err != nil {
-		log.Println(fmt.Errorf("generate folder page error: %w", err))
+		node.syncer.fileError(node.path, fmt.Errorf("generate folder page error: %w", err))
 
 		return
 	}
@@ -86,14 +93,11 @@ func (node *Node) generateChildPages() {
 
 	const folders = true
 
-	wg.Add()
-
-	go func() {
-		defer wg.Done()
+	node.syncer.wg.Go(node.syncer.runCtx, func() {
 		node.generatePlantuml(node.path)  // generate plantuml in folders with markdown in it only
 		node.iterate(processing, files)   // generate child pages for any valid files in parent page
 		node.iterate(processing, folders) // attach any image files for any valid files in parent page
-	}()
+	}, func() { node.syncer.skip(node.path) })
 }
 
 // generateFolderPage method creates a folder page in confluence for a folder
@@ -107,19 +111,12 @@ func (node *Node) generateFolderPage(hasIndex bool) error {
 
 		node.indexPage = true
 
-		masterpagecontents, err := node.processMarkDownIndex(filepath.Join(node.path, node.indexName))
-		if err != nil {
-			return err
-		}
-
-		err = node.checkConfluencePages(masterpagecontents, node.path)
-		if err != nil {
-			log.Printf("[generate folderpage] generation error for path [%s]: %v", node.path, err)
-			return err
+		if page, ok := node.syncer.unchangedPage(filepath.Join(node.path, node.indexName)); ok {
+			node.keepPage(page)
+			return nil
 		}
-		// have to do it twice...
 
-		masterpagecontents, err = node.processMarkDownIndex(filepath.Join(node.path, node.indexName))
+		masterpagecontents, err := node.processMarkDownIndex(filepath.Join(node.path, node.indexName))
 		if err != nil {
 			return err
 		}
@@ -131,6 +128,8 @@ func (node *Node) generateFolderPage(hasIndex bool) error {
 			return err
 		}
 
+		node.syncer.tree.setBranch(node.path, node.id) // links to the folder (e.g. for its images) find the index page
+
 		log.Printf("processed bespoke index file - id: [%d]", node.id)
 
 		return nil
@@ -140,15 +139,25 @@ func (node *Node) generateFolderPage(hasIndex bool) error {
 	log.Printf("no [%s] located here [%s], will generate a generic folderpage",
 		indexName, node.path)
 
+	if page, ok := node.syncer.unchangedPage(node.path); ok {
+		node.keepPage(page)
+		return nil
+	}
+
+	title, err := node.pageTitle(node.folderTitle(dir))
+	if err != nil {
+		return fmt.Errorf("absolute path [%s] - page title error: %w", fullDir, err)
+	}
+
 	masterpagecontents := &markdown.FileContents{
 		MetaData: map[string]interface{}{
-			"title": dir + " (" + fullDir + ")",
+			"title": title,
 		},
 		Body:               []byte(`{children}`),
 		BodyRepresentation: "wiki",
 	}
 
-	err := node.checkConfluencePages(masterpagecontents, node.path)
+	err = node.checkConfluencePages(masterpagecontents, node.path)
 	if err != nil {
 		log.Printf("[generate folderpage] generation error for path [%s]: %v", node.path, err)
 		return err
@@ -173,6 +182,47 @@ func (node *Node) generateTitles() (string, string) {
 	return dir, fullDir
 }
 
+// folderTitle method returns the title and path of the index file of the node's folder if it has one
+// (so the page made for the root folder before its index file is processed has the title the index file gives it)
+// otherwise the folder name and path
+func (node *Node) folderTitle(dir string) (string, string) {
+	entries, err := os.ReadDir(node.path)
+	if err != nil {
+		return dir, node.path
+	}
+
+	for _, entry := range entries {
+		fpath := filepath.Join(node.path, entry.Name())
+		if entry.IsDir() || !strings.EqualFold(entry.Name(), indexName) || !node.syncer.repoConfig.Page(fpath) {
+			continue
+		}
+
+		contents, err := os.ReadFile(filepath.Clean(fpath))
+		if err != nil {
+			return dir, node.path
+		}
+
+		return sourceTitle(fpath, contents), fpath
+	}
+
+	return dir, node.path
+}
+
+// pageTitle method returns the title of a page of the node for a file (or folder)
+// made from the title template of the repo config - by default the title followed by the folder
+// (or the title the preflight gave it if the title is a duplicate)
+func (node *Node) pageTitle(title, fpath string) (string, error) {
+	path := node.syncer.relativePath(fpath)
+
+	if title, ok := node.syncer.retitled[path]; ok {
+		return title, nil
+	}
+
+	_, abs := node.generateTitles()
+
+	return node.syncer.repoConfig.Title(config.TitleFields{Title: title, Dir: abs, Repo: node.syncer.repoName(), Path: path})
+}
+
 // generatePlantuml takes in a folder path and
 // generates a .puml file of the go code in the folder
 // then calls generatePlantumlImage method to create a picture
@@ -185,7 +235,12 @@ func (node *Node) generatePlantuml(fpath string) {
 	path, abs := node.generateTitles()
 
 	if node.root.root == nil {
-		path = rootDir
+		path = node.syncer.rootDir
+	}
+
+	if !node.syncer.repoConfig.Settings(fpath).Plantuml {
+		log.Printf("not generating plantuml for %s as the repo config turns it off", path)
+		return
 	}
 
 	log.Printf("generating plantuml text for %s", path)
@@ -223,24 +278,27 @@ func (node *Node) generatePlantuml(fpath string) {
 
 		node.uploadFile(node.path+"/"+filename+".png", node.indexPage)
 
+		title, err := node.pageTitle("plantuml-"+path, node.path+"/"+filename+".png")
+		if err != nil {
+			node.syncer.fileError(node.path+"/"+filename+".png", fmt.Errorf("page title error for path [%s]: %w", abs, err))
+			return
+		}
+
 		masterpagecontents := markdown.FileContents{
 			MetaData: map[string]interface{}{
-				"title": "plantuml-" + path + " (" + abs + ")",
+				"title": title,
 			},
 			Body: buf.Bytes(),
 		}
 
 		err = node.checkConfluencePages(&masterpagecontents, node.path+"/"+filename+".png")
 		if err != nil {
-			log.Printf("check confluence page error for path [%s]: %v", abs, err)
+			node.syncer.fileError(node.path+"/"+filename+".png",
+				fmt.Errorf("check confluence page error for path [%s]: %w", abs, err))
 		}
 
-		url := common.ConfluenceBaseURL + "/spaces/" +
-			common.ConfluenceSpace + "/pages/" + func() string {
-			return strconv.Itoa(node.id)
-		}()
-
-		log.Printf("a plantuml image was generated for location [%s] & is available at [%s]", fpath, url)
+		log.Printf("a plantuml image was generated for location [%s] & is available at [%s]", fpath,
+			node.syncer.pageURL(strconv.Itoa(node.id)))
 	}
 }
 
@@ -268,7 +326,7 @@ func (node *Node) generatePage(newPageContents *markdown.FileContents) error {
 
 	var isParentPage = true
 
-	if nodeAPIClient == nil {
+	if node.syncer.client == nil {
 		return fmt.Errorf("error: confluence API client is nil")
 	}
 
@@ -279,7 +337,7 @@ func (node *Node) generatePage(newPageContents *markdown.FileContents) error {
 			isParentPage = false
 		}
 
-		node.id, err = nodeAPIClient.CreatePage(node.masterID, newPageContents, isParentPage)
+		node.id, err = node.syncer.client.CreatePage(node.syncer.requestCtx, node.masterID, newPageContents, isParentPage)
 		if err != nil {
 			return fmt.Errorf("create page error for folder path [%s]: %w", abs, err)
 		}
@@ -287,7 +345,7 @@ func (node *Node) generatePage(newPageContents *markdown.FileContents) error {
 		return nil
 	}
 
-	node.id, err = nodeAPIClient.CreatePage(node.root.id, newPageContents, !isParentPage)
+	node.id, err = node.syncer.client.CreatePage(node.syncer.requestCtx, node.root.id, newPageContents, !isParentPage)
 	if err != nil {
 		return fmt.Errorf("create page error for folder path [%s]: %w", abs, err)
 	}
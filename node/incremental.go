@@ -0,0 +1,226 @@
+package node
+
+// incremental - only the pages of the files changed since the last synced commit are rendered and written
+// the pages of the other files are left untouched (their IDs, titles and parent pages are known from the manifest)
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// repoChanges are the files of the repo changed since the last synced commit (paths from the root of the repo)
+type repoChanges struct {
+	changed map[string]bool // files added, modified, renamed or copied (and untracked files) - their pages are rendered
+	moved   map[string]bool // files added, removed or renamed - the pages linking to them are rendered too
+}
+
+// gitChanges function returns the files changed in dir since commit since - committed or not
+func gitChanges(dir, since string) (*repoChanges, error) {
+	out, err := exec.Command("git", "-C", dir, "diff", "--find-renames", "--name-status", "-z",
+		"--relative", since).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff error: %w", err)
+	}
+
+	found := parseChanges(out)
+
+	untracked, err := exec.Command("git", "-C", dir, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files error: %w", err)
+	}
+
+	for _, file := range bytes.Split(bytes.TrimRight(untracked, "\x00"), []byte{0}) {
+		if len(file) > 0 {
+			found.changed[string(file)] = true
+			found.moved[string(file)] = true
+		}
+	}
+
+	return found, nil
+}
+
+// parseChanges function reads the output of git diff --name-status -z
+// and returns the files changed (and the files added, removed or renamed)
+func parseChanges(out []byte) *repoChanges {
+	found := &repoChanges{changed: map[string]bool{}, moved: map[string]bool{}}
+
+	fields := bytes.Split(bytes.TrimRight(out, "\x00"), []byte{0})
+
+	for index := 0; index+1 < len(fields); index++ {
+		status := string(fields[index])
+		file := string(fields[index+1])
+
+		switch {
+		case strings.HasPrefix(status, "R") || strings.HasPrefix(status, "C"):
+			if index+2 >= len(fields) {
+				return found
+			}
+
+			renamed := string(fields[index+2])
+
+			if strings.HasPrefix(status, "R") {
+				found.moved[file] = true
+			}
+
+			found.changed[renamed] = true
+			found.moved[renamed] = true
+
+			index += 2
+		case strings.HasPrefix(status, "D"):
+			found.moved[file] = true
+
+			index++
+		case strings.HasPrefix(status, "A"):
+			found.changed[file] = true
+			found.moved[file] = true
+
+			index++
+		default: // modified, type changed or unmerged
+			found.changed[file] = true
+
+			index++
+		}
+	}
+
+	return found
+}
+
+// planUnchanged method works out which planned pages are left untouched this run: pages whose file has not changed
+// since the last synced commit, that do not link to a file added, removed or renamed since, and that the manifest
+// records with the same page ID, title and parent page - every page is rendered for a Full sync,
+// there is no synced commit or manifest, git cannot tell what changed or the repo config (.mtc.yaml) changed
+func (syncer *Syncer) planUnchanged(pages []*plannedPage) {
+	syncer.unchanged = map[string]*plannedPage{}
+	syncer.changes = nil
+
+	switch {
+	case syncer.options.Full:
+		log.Println("full sync - every page is rendered")
+		return
+	case syncer.lastSynced == "" || len(syncer.manifest) == 0:
+		log.Println("no synced commit or manifest recorded yet - every page is rendered")
+		return
+	}
+
+	found, err := gitChanges(syncer.projectRoot, syncer.lastSynced)
+	if err != nil {
+		log.Printf("could not find the files changed since [%s] - every page is rendered: %v", syncer.lastSynced, err)
+		return
+	}
+
+	if found.changed[config.FileName] || found.moved[config.FileName] {
+		log.Printf("%s changed since [%s] - every page is rendered", config.FileName, syncer.lastSynced)
+		return
+	}
+
+	syncer.changes = found
+
+	var total int
+
+	walkPages(pages, func(page *plannedPage) {
+		total++
+
+		synced, ok := syncer.manifest[syncer.managedPath(page.path)]
+
+		switch {
+		case !ok, synced.ID != page.id, synced.Title != page.title, synced.ParentID != page.parentID:
+			return // the page is new, or was recreated, retitled or moved
+		case syncer.changes.changed[syncer.relativePath(page.path)], syncer.linksTo(page, syncer.changes.moved):
+			return
+		}
+
+		syncer.unchanged[page.path] = page
+	})
+
+	log.Printf("incremental sync - [%d] of [%d] pages are left untouched as their files have not changed since [%s]",
+		len(syncer.unchanged), total, syncer.lastSynced)
+}
+
+// linksTo method returns true if the file of the page links to one of the files (paths from the root of the repo)
+// or to the folder of one of the index files
+func (syncer *Syncer) linksTo(page *plannedPage, files map[string]bool) bool {
+	if len(files) == 0 {
+		return false
+	}
+
+	contents, err := os.ReadFile(filepath.Clean(page.path))
+	if err != nil { // a generic folder page has no file
+		return false
+	}
+
+	dir := path.Dir(syncer.relativePath(page.path))
+
+	for _, link := range markdown.Links(contents) {
+		link = strings.SplitN(link, "#", 2)[0] //nolint:gomnd // the link without its anchor
+
+		if link == "" || strings.Contains(link, ":") { // an anchor in the page or a url
+			continue
+		}
+
+		if unescaped, err := url.PathUnescape(link); err == nil {
+			link = unescaped
+		}
+
+		target := path.Join(dir, link)
+		if strings.HasPrefix(link, "/") { // from the root of the repo
+			target = path.Clean(strings.TrimPrefix(link, "/"))
+		}
+
+		if files[target] || files[path.Join(target, indexName)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unchangedPage method returns the planned page of the file (or folder) if it is left untouched this run
+func (syncer *Syncer) unchangedPage(fpath string) (*plannedPage, bool) {
+	page, ok := syncer.unchanged[fpath]
+
+	return page, ok
+}
+
+// keepPage method leaves the page of an unchanged file untouched - recording it as checkConfluencePages records
+// the pages it writes, so it is not deleted and is kept in the manifest
+func (node *Node) keepPage(page *plannedPage) {
+	node.id = page.id
+
+	node.addContents(&markdown.FileContents{MetaData: map[string]interface{}{"title": page.title}})
+
+	node.syncer.tree.mapSem <- struct{}{}
+
+	defer func() { <-node.syncer.tree.mapSem }()
+
+	for _, link := range page.links {
+		node.syncer.tree.branches[link] = strconv.Itoa(page.id)
+	}
+
+	node.syncer.tree.pages[node.syncer.managedPath(page.path)] = node.syncer.manifest[node.syncer.managedPath(page.path)]
+
+	log.Printf("[%s] has not changed since the last sync - page [%d] is left untouched",
+		node.syncer.relativePath(page.path), page.id)
+
+	node.syncer.recordPage(page.path, ResultUnchanged, page.title, page.id)
+}
+
+// unchangedImage method returns true if the image has not changed since the last synced commit
+// and the page it is attached to is the page the manifest records for its folder (so it was uploaded to it then)
+func (syncer *Syncer) unchangedImage(fpath string, pageID int) bool {
+	if syncer.changes == nil || syncer.changes.changed[syncer.relativePath(fpath)] {
+		return false
+	}
+
+	return pageID != 0 && syncer.manifestID(filepath.Dir(fpath)) == pageID
+}
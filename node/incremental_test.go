@@ -0,0 +1,152 @@
+package node
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	markdown "github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+func TestParseChanges(t *testing.T) {
+	out := []byte("M\x00docs/readme.md\x00R087\x00docs/setup.md\x00docs/installation.md\x00" +
+		"A\x00docs/new.md\x00C100\x00docs/a.md\x00docs/b.md\x00D\x00docs/old.md\x00")
+
+	found := parseChanges(out)
+
+	assert.Equal(t, map[string]bool{"docs/readme.md": true, "docs/installation.md": true, "docs/new.md": true,
+		"docs/b.md": true}, found.changed)
+	assert.Equal(t, map[string]bool{"docs/setup.md": true, "docs/installation.md": true, "docs/new.md": true,
+		"docs/b.md": true, "docs/old.md": true}, found.moved)
+	assert.Empty(t, parseChanges(nil).changed)
+}
+
+// TestIncrementalSync syncs a git repo, commits a change to one file and a file another page links to - the next run
+// only writes the changed page, the new page and the page linking to it, the run after writes no page
+// and a full run only writes the page edited by hand since
+func TestIncrementalSync(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = os.Chdir(wd) }()
+
+	if err = os.Chdir(t.TempDir()); err != nil { // links are resolved from the relative path of the repo
+		t.Fatal(err)
+	}
+
+	repo := "docs"
+
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@test"},
+			args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+
+	write := func(name, contents string) {
+		err := os.WriteFile(filepath.Join(repo, name), []byte(contents), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = os.MkdirAll(repo, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	git("init", "--quiet")
+	write("readme.md", "# Docs\n\nsee [setup](setup.md) and [guide](guide.md)\n")
+	write("guide.md", "# Guide\n\nthe guide\n")
+	write("faq.md", "# FAQ\n\nthe questions\n")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "docs")
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: repo, ParentID: masterID}, server.Client())
+
+	syncRepo := func() []string {
+		requests := len(server.Requests())
+
+		assert.True(t, syncer.Start(context.Background()))
+		assert.NoError(t, syncer.Delete())
+		assert.NoError(t, syncer.MarkSynced())
+		assert.NoError(t, syncer.SaveManifest())
+
+		return server.Requests()[requests:]
+	}
+
+	page := func(title string) confluencetest.Page {
+		for _, found := range server.Pages() {
+			if strings.HasPrefix(found.Title, title+" (") {
+				return found
+			}
+		}
+
+		t.Fatalf("no page titled %s", title)
+
+		return confluencetest.Page{}
+	}
+
+	written := func(requests []string, title string) bool {
+		return count(requests, "PUT /rest/api/content/"+strconv.Itoa(page(title).ID)) > 0
+	}
+
+	syncRepo()
+
+	write("guide.md", "# Guide\n\nthe new guide\n")
+	write("setup.md", "# Setup\n\nthe setup\n")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "setup")
+
+	requests := syncRepo()
+
+	assert.True(t, written(requests, "Guide"))
+	assert.True(t, written(requests, "Setup"))
+	assert.True(t, written(requests, "Docs")) // links to the new file
+	assert.False(t, written(requests, "FAQ"))
+
+	assert.Contains(t, page("Guide").Body, "the new guide")
+	assert.Contains(t, page("Docs").Body, `data-linked-resource-id="`+strconv.Itoa(page("Setup").ID)+`"`)
+	assert.Len(t, server.Pages(), 5)
+
+	faq := page("FAQ")
+
+	_, err = server.Client().UpdatePage(context.Background(), faq.ID, 0, int64(faq.Version), &markdown.FileContents{
+		MetaData: map[string]interface{}{"title": faq.Title}, Body: []byte("<p>edited by hand</p>")},
+		confluence.PageResults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests = syncRepo()
+
+	for _, title := range []string{"Docs", "Guide", "Setup", "FAQ"} {
+		assert.False(t, written(requests, title), title)
+	}
+
+	syncer.options.Full = true
+
+	requests = syncRepo()
+
+	for _, title := range []string{"Docs", "Guide", "Setup"} { // rendered again but unchanged
+		assert.False(t, written(requests, title), title)
+	}
+
+	assert.True(t, written(requests, "FAQ"))
+	assert.Contains(t, page("FAQ").Body, "the questions")
+	assert.Len(t, server.Pages(), 5)
+}
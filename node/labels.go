@@ -0,0 +1,99 @@
+package node
+
+// labels - methods for keeping confluence page labels in step with frontmatter labels/tags
+
+import (
+	"fmt"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// wantedLabels method returns the labels the page of the file should have: the labels/tags in the page frontmatter
+// plus the labels of the sync options and the labels the repo config sets for the file path
+func (syncer *Syncer) wantedLabels(newPageContents *markdown.FileContents, fpath string) []string {
+	desired := append(newPageContents.Labels(), syncer.options.Labels...)
+
+	return markdown.NormaliseLabels(append(desired, syncer.repoConfig.Settings(fpath).Labels...))
+}
+
+// appliedLabels function returns the labels the tool applied to the page found for a file
+// (recorded in its managed property) - none if no page was found or it is not managed
+func appliedLabels(pageResult *confluence.PageResults) []string {
+	if pageResult == nil || len(pageResult.Results) == 0 {
+		return nil
+	}
+
+	managed, ok := pageResult.Results[0].Managed()
+	if !ok {
+		return nil
+	}
+
+	return managed.Labels
+}
+
+// syncLabels method reconciles the labels on the node's confluence page with the desired labels (see wantedLabels)
+// missing labels are added and the labels the tool applied before (applied) that are no longer wanted are removed -
+// labels added to the page by hand are never removed
+func (node *Node) syncLabels(desired, applied []string) error {
+	if node.id == 0 {
+		return nil
+	}
+
+	current, err := node.syncer.client.GetLabels(node.syncer.requestCtx, node.id)
+	if err != nil {
+		return fmt.Errorf("get labels error: %w", err)
+	}
+
+	toAdd, toRemove := diffLabels(current, desired, applied)
+
+	for index := range toRemove {
+		err = node.syncer.client.RemoveLabel(node.syncer.requestCtx, node.id, toRemove[index])
+		if err != nil {
+			return fmt.Errorf("remove label error: %w", err)
+		}
+	}
+
+	err = node.syncer.client.AddLabels(node.syncer.requestCtx, node.id, toAdd)
+	if err != nil {
+		return fmt.Errorf("add labels error: %w", err)
+	}
+
+	return nil
+}
+
+// diffLabels function returns the labels in desired that are not in current (to add)
+// and the labels in current that were applied but are not in desired (to remove)
+func diffLabels(current, desired, applied []string) ([]string, []string) {
+	var toAdd, toRemove []string
+
+	currentSet := map[string]bool{}
+
+	for index := range current {
+		currentSet[current[index]] = true
+	}
+
+	desiredSet := map[string]bool{}
+
+	for index := range desired {
+		desiredSet[desired[index]] = true
+
+		if !currentSet[desired[index]] {
+			toAdd = append(toAdd, desired[index])
+		}
+	}
+
+	appliedSet := map[string]bool{}
+
+	for index := range applied {
+		appliedSet[applied[index]] = true
+	}
+
+	for index := range current {
+		if appliedSet[current[index]] && !desiredSet[current[index]] {
+			toRemove = append(toRemove, current[index])
+		}
+	}
+
+	return toAdd, toRemove
+}
@@ -0,0 +1,85 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+func TestSyncLabels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := NewMockAPIClienter(mockCtrl)
+
+	// stale was applied by the tool and is no longer wanted - by-hand was added in confluence so it is kept
+	mock.EXPECT().GetLabels(gomock.Any(), 42).Return([]string{"docs", "stale", "by-hand"}, nil)
+	mock.EXPECT().RemoveLabel(gomock.Any(), 42, "stale").Return(nil)
+	mock.EXPECT().AddLabels(gomock.Any(), 42, []string{"runbook"}).Return(nil)
+
+	syncer := NewSyncer(Options{Labels: []string{"docs"}}, mock)
+
+	node := newNode(syncer)
+	node.id = 42
+
+	desired := syncer.wantedLabels(&markdown.FileContents{
+		MetaData: map[string]interface{}{
+			"title":  "page",
+			"labels": []interface{}{"Runbook"},
+		},
+	}, "testfolder/page.md")
+
+	assert.Equal(t, []string{"runbook", "docs"}, desired)
+	assert.NoError(t, node.syncLabels(desired, []string{"docs", "stale"}))
+}
+
+// TestSync_HandAddedLabel checks a label added to a page by hand survives a sync
+// while a label the tool applied that is no longer wanted is removed
+func TestSync_HandAddedLabel(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	ctx := context.Background()
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID, Labels: []string{"docs", "old"},
+		Full: true}, server.Client())
+
+	assert.True(t, syncer.Start(ctx))
+	assert.NoError(t, syncer.Delete())
+
+	hello, _ := server.PageByTitle("file within readme! (testfolder/file/downhere)")
+	assert.Equal(t, []string{"docs", "old"}, hello.Labels)
+
+	found, err := server.Client().FindPage(ctx, hello.Title, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managed, ok := found.Results[0].Managed()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"docs", "old"}, managed.Labels)
+
+	assert.NoError(t, server.Client().AddLabels(ctx, hello.ID, []string{"by-hand"}))
+
+	syncer = NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID, Labels: []string{"docs"},
+		Full: true}, server.Client())
+
+	assert.True(t, syncer.Start(ctx))
+	assert.NoError(t, syncer.Delete())
+
+	hello, _ = server.PageByTitle(hello.Title)
+	assert.ElementsMatch(t, []string{"docs", "by-hand"}, hello.Labels)
+
+	found, err = server.Client().FindPage(ctx, hello.Title, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managed, _ = found.Results[0].Managed()
+	assert.Equal(t, []string{"docs"}, managed.Labels)
+}
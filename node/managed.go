@@ -0,0 +1,204 @@
+package node
+
+// managed - methods for marking pages as managed by the tool and checking page ownership
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/common"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// repoName method returns the name of the repo the pages are generated from
+// (the github repository when running as an action, else the root folder name)
+func (syncer *Syncer) repoName() string {
+	if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+		return repo
+	}
+
+	return syncer.rootDir
+}
+
+// managedPath method returns the file path relative to the project root,
+// index files (readme.md) are given the path of the folder page they are used for
+func (syncer *Syncer) managedPath(fpath string) string {
+	if strings.EqualFold(filepath.Base(fpath), indexName) {
+		fpath = filepath.Dir(fpath)
+	}
+
+	rel, err := filepath.Rel(syncer.projectRoot, fpath)
+	if err != nil {
+		return filepath.ToSlash(fpath)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// relativePath method returns the file path relative to the project root (index files keep their own path)
+func (syncer *Syncer) relativePath(fpath string) string {
+	rel, err := filepath.Rel(syncer.projectRoot, fpath)
+	if err != nil {
+		return filepath.ToSlash(fpath)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// contentHash function returns the sha256 of the page body
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// newManagedProperty method returns the managed property to store on the page for a file
+func (syncer *Syncer) newManagedProperty(contents *markdown.FileContents, fpath string) confluence.ManagedProperty {
+	return confluence.ManagedProperty{
+		Repo:        syncer.repoName(),
+		Path:        syncer.managedPath(fpath),
+		Hash:        contentHash(contents.Body),
+		ToolVersion: common.Version,
+	}
+}
+
+// isManaged method returns true if the page was created by the tool for this repo
+func (syncer *Syncer) isManaged(page confluence.Page) bool {
+	managed, ok := page.Managed()
+
+	return ok && managed.Repo == syncer.repoName()
+}
+
+// checkOwnership method returns an error if the page found for a file is managed
+// by the tool for a different file (or repo) - so it is not overwritten
+// the page managed for the file before it was renamed is owned by the file
+// pages that are not managed at all are only adopted (pages created before properties were stored)
+// if they are in the tree the tool syncs (see inTree) - parentID is the page the file's page is made under
+func (syncer *Syncer) checkOwnership(page confluence.Page, want confluence.ManagedProperty, parentID int) error {
+	managed, ok := page.Managed()
+	if !ok {
+		if !syncer.inTree(page, parentID) {
+			return fmt.Errorf("page [%s] with title [%s] is not managed by the tool and is not under the parent page - "+
+				"refusing to adopt it for [%s] file [%s]", page.ID, page.Title, want.Repo, want.Path)
+		}
+
+		log.Printf("page [%s] with title [%s] is not marked as managed - adopting it for [%s]",
+			page.ID, page.Title, want.Path)
+
+		return nil
+	}
+
+	if old, renamed := syncer.renamedFrom(want.Path); renamed && managed.Repo == want.Repo && managed.Path == old {
+		return nil
+	}
+
+	if managed.Repo != want.Repo || managed.Path != want.Path {
+		return fmt.Errorf("page [%s] with title [%s] is managed for [%s] file [%s] - refusing to overwrite it with [%s] file [%s]",
+			page.ID, page.Title, managed.Repo, managed.Path, want.Repo, want.Path)
+	}
+
+	return nil
+}
+
+// inTree method returns true if the page is under the configured parent page, the root page of the repo
+// or parentID (pages found with only their own parent in their ancestors are checked against it)
+func (syncer *Syncer) inTree(page confluence.Page, parentID int) bool {
+	rootID := 0
+	if syncer.root != nil {
+		rootID = syncer.root.id
+	}
+
+	for _, ancestor := range page.Ancestors {
+		if ancestor.ID == 0 {
+			continue
+		}
+
+		if ancestor.ID == syncer.options.ParentID || ancestor.ID == rootID || ancestor.ID == parentID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parentID method returns the confluence page ID the node's page is created under
+func (node *Node) parentID() int {
+	if node.root == nil {
+		return node.masterID
+	}
+
+	return node.root.id
+}
+
+// findManagedPage method looks through the pages under the node's parent page for the
+// page managed for the file path - so pages are found even if their title has changed
+func (node *Node) findManagedPage(want confluence.ManagedProperty) (*confluence.PageResults, error) {
+	parentID := node.parentID()
+	if parentID == 0 {
+		return nil, nil
+	}
+
+	children, err := node.syncer.client.FindPage(node.syncer.requestCtx, strconv.Itoa(parentID), true)
+	if err != nil || children == nil {
+		return nil, err
+	}
+
+	for index := range children.Results {
+		managed, ok := children.Results[index].Managed()
+		if ok && managed.Repo == want.Repo && managed.Path == want.Path {
+			return &confluence.PageResults{Results: []confluence.Page{children.Results[index]}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// markManaged method stores the managed property on the node's page
+// unless the page found already has the same property
+func (node *Node) markManaged(pageResult *confluence.PageResults, want confluence.ManagedProperty) error {
+	if node.id == 0 {
+		return nil
+	}
+
+	if pageResult != nil && len(pageResult.Results) > 0 {
+		managed, ok := pageResult.Results[0].Managed()
+		if ok && sameManaged(*managed, want) {
+			return nil
+		}
+	}
+
+	return node.syncer.client.SetProperty(node.syncer.requestCtx, node.id, confluence.ManagedPropertyKey, want)
+}
+
+// sameManaged function returns true if the managed properties are the same
+func sameManaged(a, b confluence.ManagedProperty) bool {
+	return a.Repo == b.Repo && a.Path == b.Path && a.Hash == b.Hash && a.ToolVersion == b.ToolVersion &&
+		a.Version == b.Version && strings.Join(a.Labels, ",") == strings.Join(b.Labels, ",")
+}
+
+// pageUnchanged method returns true if the page found for the file does not need writing again: it is under the
+// node's parent page and either the tool rendered it from the same source with the same title and it was not edited
+// since (the hash and version in its managed property) or it has the same title and body in canonical form
+func (node *Node) pageUnchanged(current confluence.Page, contents *markdown.FileContents,
+	want confluence.ManagedProperty) bool {
+	title, _ := contents.MetaData["title"].(string)
+
+	if parentID := node.parentID(); parentID != 0 && current.ParentID() != parentID {
+		return false // the page is moved
+	}
+
+	managed, ok := current.Managed()
+	if ok && managed.Version != 0 && managed.Version == current.Version.Number {
+		return managed.Hash == want.Hash && (current.Title == "" || current.Title == title)
+	}
+
+	return confluence.PageUnchanged(current, confluence.Page{Title: title,
+		Body: confluence.BodyObj{Storage: confluence.StorageObj{Value: string(contents.Body)}}})
+}
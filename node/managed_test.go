@@ -0,0 +1,174 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+func managedPage(t *testing.T, property confluence.ManagedProperty) confluence.Page {
+	value, err := json.Marshal(property)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return confluence.Page{
+		ID:    "7",
+		Title: "page",
+		Metadata: &confluence.MetadataObj{Properties: map[string]confluence.Property{
+			confluence.ManagedPropertyKey: {Key: confluence.ManagedPropertyKey, Value: value},
+		}},
+	}
+}
+
+func TestCheckOwnership(t *testing.T) {
+	want := confluence.ManagedProperty{Repo: "org/repo", Path: "docs/setup.md"}
+
+	testInputs := []struct {
+		name        string
+		page        confluence.Page
+		expectError bool
+	}{
+		{
+			name: "unmanaged page under the parent page is adopted",
+			page: confluence.Page{ID: "7", Title: "page", Ancestors: []confluence.AncestorObj{{ID: 1}, {ID: 3}}},
+		},
+		{
+			name: "unmanaged page under the page the file's page is made under is adopted",
+			page: confluence.Page{ID: "7", Title: "page", Ancestors: []confluence.AncestorObj{{ID: 5}}},
+		},
+		{
+			name:        "unmanaged page outside the tree",
+			page:        confluence.Page{ID: "7", Title: "page", Ancestors: []confluence.AncestorObj{{ID: 1}, {ID: 2}}},
+			expectError: true,
+		},
+		{
+			name:        "unmanaged page at the top of the space",
+			page:        confluence.Page{ID: "7", Title: "page"},
+			expectError: true,
+		},
+		{
+			name: "page managed for the same file",
+			page: managedPage(t, confluence.ManagedProperty{Repo: "org/repo", Path: "docs/setup.md", Hash: "old"}),
+		},
+		{
+			name:        "page managed for another file",
+			page:        managedPage(t, confluence.ManagedProperty{Repo: "org/repo", Path: "docs/other.md"}),
+			expectError: true,
+		},
+		{
+			name:        "page managed for another repo",
+			page:        managedPage(t, confluence.ManagedProperty{Repo: "org/other", Path: "docs/setup.md"}),
+			expectError: true,
+		},
+	}
+
+	for _, test := range testInputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := NewSyncer(Options{ParentID: 3}, nil).checkOwnership(test.page, want, 5)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestManagedPath(t *testing.T) {
+	syncer := NewSyncer(Options{}, nil)
+	syncer.projectRoot = "/github/workspace/repo"
+
+	assert.Equal(t, "docs/setup.md", syncer.managedPath("/github/workspace/repo/docs/setup.md"))
+	assert.Equal(t, "docs", syncer.managedPath("/github/workspace/repo/docs/README.md"))
+	assert.Equal(t, ".", syncer.managedPath("/github/workspace/repo"))
+}
+
+func TestPageUnchanged(t *testing.T) {
+	contents := &markdown.FileContents{MetaData: map[string]interface{}{"title": "page"}, Body: []byte("<p>setup</p>")}
+	want := confluence.ManagedProperty{Repo: "org/repo", Path: "docs/setup.md", Hash: contentHash(contents.Body)}
+
+	written := func(hash string, version, pageVersion, parentID int, body string) confluence.Page {
+		page := managedPage(t, confluence.ManagedProperty{Repo: "org/repo", Path: "docs/setup.md", Hash: hash,
+			Version: version})
+		page.Version = confluence.VersionObj{Number: pageVersion}
+		page.Ancestors = []confluence.AncestorObj{{ID: parentID}}
+		page.Body.Storage.Value = body
+
+		return page
+	}
+
+	testInputs := []struct {
+		name      string
+		page      confluence.Page
+		unchanged bool
+	}{
+		{
+			name:      "written from the same source",
+			page:      written(want.Hash, 3, 3, 1, "<p>stored differently</p>"),
+			unchanged: true,
+		},
+		{
+			name: "written from another source",
+			page: written("old", 3, 3, 1, "<p>setup</p>"),
+		},
+		{
+			name: "edited by hand since it was written",
+			page: written(want.Hash, 3, 4, 1, "<p>edited</p>"),
+		},
+		{
+			name:      "edited by hand back to the same body",
+			page:      written(want.Hash, 3, 4, 1, "<p>setup</p>\n"),
+			unchanged: true,
+		},
+		{
+			name: "under another page",
+			page: written(want.Hash, 3, 3, 2, "<p>setup</p>"),
+		},
+	}
+
+	for _, test := range testInputs {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			node := &Node{syncer: NewSyncer(Options{}, nil), masterID: 1}
+
+			assert.Equal(t, test.unchanged, node.pageUnchanged(test.page, contents, want))
+		})
+	}
+}
+
+// TestSync_UnmanagedPageOutsideTree checks a page of the space the tool does not manage - and that is not under
+// the parent page - is never adopted (or written or removed) when a file is given its title
+func TestSync_UnmanagedPageOutsideTree(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+	elsewhereID := server.AddPage(0, "elsewhere", "")
+	collidingID := server.AddPage(elsewhereID, "file within readme! (testfolder/file/downhere)", "<p>someone else's</p>")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID, DuplicateTitles: config.DuplicatesWarn},
+		server.Client())
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	report := syncer.Report()
+	assert.Equal(t, 1, report.Failed())
+	assert.Equal(t, "file/downhere/hello.md", report.Files[0].File)
+	assert.Contains(t, report.Files[0].Error, "refusing to adopt it")
+
+	colliding, _ := server.Page(collidingID)
+	assert.Equal(t, confluencetest.StatusCurrent, colliding.Status)
+	assert.Equal(t, 1, colliding.Version)
+	assert.Equal(t, elsewhereID, colliding.ParentID)
+	assert.Empty(t, colliding.Properties)
+}
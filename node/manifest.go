@@ -0,0 +1,133 @@
+package node
+
+// manifest - the page synced from each file of the repo, kept on the root page of the repo (and optionally in a
+// local file) so the next run knows the ID of every page without looking each of them up by title
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+// manifestID method returns the ID of the page recorded in the manifest for the file (0 if there is none)
+func (syncer *Syncer) manifestID(fpath string) int {
+	return syncer.manifest[syncer.managedPath(fpath)].ID
+}
+
+// useManifest method keeps the pages of the manifest read from source if it is the manifest of this repo
+func (syncer *Syncer) useManifest(found *confluence.ManifestProperty, source string) {
+	if found == nil || found.Repo != syncer.repoName() || len(found.Pages) == 0 {
+		return
+	}
+
+	syncer.manifest = found.Pages
+
+	log.Printf("read the pages of [%d] files from the manifest %s", len(syncer.manifest), source)
+}
+
+// loadManifestFile method reads the manifest of the last run from the manifest file (if one is set and it exists)
+func (syncer *Syncer) loadManifestFile() {
+	syncer.manifest = map[string]confluence.ManifestPage{}
+
+	if syncer.options.ManifestFile == "" {
+		return
+	}
+
+	contents, err := os.ReadFile(filepath.Clean(syncer.options.ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	if err != nil {
+		log.Printf("could not read the manifest file - pages are looked up instead: %v", err)
+		return
+	}
+
+	found := confluence.ManifestProperty{}
+
+	err = json.Unmarshal(contents, &found)
+	if err != nil {
+		log.Printf("could not read the manifest file [%s] - pages are looked up instead: %v", syncer.options.ManifestFile, err)
+		return
+	}
+
+	syncer.useManifest(&found, "file ["+syncer.options.ManifestFile+"]")
+}
+
+// loadManifest method reads the manifest of the last run from the property on the node's page
+// (the root page of the repo) unless it was read from the manifest file
+func (node *Node) loadManifest() {
+	if len(node.syncer.manifest) > 0 || node.syncer.client == nil || node.id == 0 {
+		return
+	}
+
+	property, err := node.syncer.client.GetProperty(node.syncer.requestCtx, node.id, confluence.ManifestPropertyKey)
+	if err != nil {
+		log.Printf("could not read the manifest - pages are looked up instead: %v", err)
+		return
+	}
+
+	if property == nil {
+		log.Println("no manifest recorded yet - pages are looked up instead")
+		return
+	}
+
+	found := confluence.ManifestProperty{}
+
+	err = json.Unmarshal(property.Value, &found)
+	if err != nil {
+		log.Printf("could not read the manifest - pages are looked up instead: %v", err)
+		return
+	}
+
+	node.syncer.useManifest(&found, "on the root page")
+}
+
+// SaveManifest method records the page synced from each file this run on the root page of the repo
+// and in the manifest file if one is set
+func (syncer *Syncer) SaveManifest() error {
+	if syncer.client == nil || syncer.root == nil || syncer.root.id == 0 {
+		return nil
+	}
+
+	synced := confluence.ManifestProperty{Repo: syncer.repoName(), Pages: syncer.tree.syncedPages()}
+
+	if syncer.options.ManifestFile != "" {
+		contents, err := json.MarshalIndent(synced, "", "  ")
+		if err != nil {
+			return fmt.Errorf("save manifest json marshal error: %w", err)
+		}
+
+		err = os.WriteFile(filepath.Clean(syncer.options.ManifestFile), contents, 0o600)
+		if err != nil {
+			return fmt.Errorf("save manifest error: %w", err)
+		}
+	}
+
+	err := syncer.client.SetProperty(syncer.requestCtx, syncer.root.id, confluence.ManifestPropertyKey, synced)
+	if err != nil {
+		return fmt.Errorf("save manifest error: %w", err)
+	}
+
+	return nil
+}
+
+// syncedPages method returns the page synced from each file this run (by managed path)
+func (tree *Tree) syncedPages() map[string]confluence.ManifestPage {
+	tree.mapSem <- struct{}{}
+
+	defer func() { <-tree.mapSem }()
+
+	pages := make(map[string]confluence.ManifestPage, len(tree.pages))
+
+	for path, page := range tree.pages {
+		pages[path] = page
+	}
+
+	return pages
+}
@@ -0,0 +1,97 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+)
+
+// TestManifest syncs testfolder three times - the first run renders each page once with its relative links resolved
+// and records the manifest on the root page, the later runs find every page from the manifest file
+// and write none of them as nothing changed
+func TestManifest(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID}, server.Client())
+
+	syncTestfolder := func() []string {
+		requests := len(server.Requests())
+
+		assert.True(t, syncer.Start(context.Background()))
+		assert.NoError(t, syncer.Delete())
+		assert.NoError(t, syncer.SaveManifest())
+
+		return server.Requests()[requests:]
+	}
+
+	requests := syncTestfolder()
+
+	root, _ := server.PageByTitle("INDEX readme (testfolder)")
+	folder, _ := server.PageByTitle("a deeply nested readme file (testfolder/file/downhere)")
+	hello, _ := server.PageByTitle("file within readme! (testfolder/file/downhere)")
+
+	assert.Contains(t, root.Body, `data-linked-resource-id="`+strconv.Itoa(hello.ID)+`"`)
+
+	for _, id := range []int{root.ID, folder.ID, hello.ID} { // created empty then written once
+		assert.Equal(t, 1, count(requests, "PUT /rest/api/content/"+strconv.Itoa(id)), "page %d", id)
+	}
+
+	recorded := confluence.ManifestProperty{}
+	assert.NoError(t, json.Unmarshal(root.Properties[confluence.ManifestPropertyKey], &recorded))
+	assert.Equal(t, syncer.repoName(), recorded.Repo)
+
+	ids := map[string]int{}
+	for path, page := range recorded.Pages {
+		ids[path] = page.ID
+	}
+
+	assert.Equal(t, map[string]int{".": root.ID, "file/downhere": folder.ID, "file/downhere/hello.md": hello.ID}, ids)
+
+	syncer.options.ManifestFile = filepath.Join(t.TempDir(), "manifest.json")
+
+	for run := 1; run <= 2; run++ { // the first run writes the manifest file and the second reads it
+		requests = syncTestfolder()
+
+		assert.Zero(t, count(requests, "POST /rest/api/content"), "run %d", run)
+		assert.Len(t, server.Pages(), 4, "run %d", run)
+
+		for _, id := range []int{root.ID, folder.ID, hello.ID} { // nothing changed so no page is given a new version
+			assert.Zero(t, count(requests, "PUT /rest/api/content/"+strconv.Itoa(id)), "run %d page %d", run, id)
+		}
+	}
+
+	contents, err := os.ReadFile(syncer.options.ManifestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written := confluence.ManifestProperty{}
+	assert.NoError(t, json.Unmarshal(contents, &written))
+	assert.Equal(t, recorded.Repo, written.Repo)
+	assert.Len(t, written.Pages, 3)
+	assert.Equal(t, hello.ID, written.Pages["file/downhere/hello.md"].ID)
+}
+
+// count function returns the number of requests made with the method and path (ignoring the query)
+func count(requests []string, request string) int {
+	var found int
+
+	for _, made := range requests {
+		if strings.SplitN(made, "?", 2)[0] == request {
+			found++
+		}
+	}
+
+	return found
+}
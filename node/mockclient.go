@@ -1,22 +1,35 @@
 package node
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/xiatechs/markdown-to-confluence/confluence"
 	"github.com/xiatechs/markdown-to-confluence/markdown"
 )
 
 /*
-	CreatePage(root int, contents *markdown.FileContents, isroot bool) (int, error)
-	DeletePage(pageID int) error
-	UpdatePage(pageID int, pageVersion int64, pageContents *markdown.FileContents,
-		originalPage confluence.PageResults) (bool, error)
-	FindPage(title string, many bool) (*confluence.PageResults, error)
-	UploadAttachment(filename string, id int, index bool, indexid int) error
+CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error)
+DeletePage(ctx context.Context, pageID int) error
+UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents,
+
+	originalPage confluence.PageResults) (int, error)
+
+FindPage(ctx context.Context, title string, many bool) (*confluence.PageResults, error)
+FindManagedPages(ctx context.Context, pageID int) ([]confluence.Page, error)
+UploadAttachment(ctx context.Context, filename string, id int, index bool, indexid int) (bool, error)
+GetLabels(ctx context.Context, pageID int) ([]string, error)
+AddLabels(ctx context.Context, pageID int, labels []string) error
+RemoveLabel(ctx context.Context, pageID int, label string) error
+GetProperty(ctx context.Context, pageID int, key string) (*confluence.Property, error)
+SetProperty(ctx context.Context, pageID int, key string, value interface{}) error
+ListAttachments(ctx context.Context, pageID int) ([]confluence.Attachment, error)
+DeleteAttachment(ctx context.Context, attachmentID string) error
 */
 type iterator struct { // enables pointer arithmetic
+	mu       sync.Mutex // race blocker
 	mockiter int
 	isroot   bool
 	pages    []*Page
@@ -35,8 +48,6 @@ type mockclient struct {
 	i *iterator
 }
 
-var s = make(chan bool, 1)
-
 func (m mockclient) Print() {
 	sort.Slice(m.i.pages, func(i, j int) bool {
 		return m.i.pages[i].id > m.i.pages[j].id
@@ -52,10 +63,9 @@ func (m mockclient) Print() {
 	}
 }
 
-var mocksem = make(chan bool, 1)
-
 func (m mockclient) GetPages() []Page {
-	mocksem <- true
+	m.i.mu.Lock()
+	defer m.i.mu.Unlock()
 
 	pages := []Page{}
 
@@ -63,12 +73,10 @@ func (m mockclient) GetPages() []Page {
 		pages = append(pages, *page)
 	}
 
-	<-mocksem
-
 	return pages
 }
 
-//nolint: ineffassign // is ok
+// nolint: ineffassign // is ok
 func (i *iterator) append(root int, contents *markdown.FileContents, isroot bool) int {
 	var exists bool
 
@@ -97,8 +105,9 @@ func (i *iterator) append(root int, contents *markdown.FileContents, isroot bool
 	return i.mockiter
 }
 
-func (m mockclient) CreatePage(root int, contents *markdown.FileContents, _ bool) (int, error) {
-	s <- true // race blocker
+func (m mockclient) CreatePage(ctx context.Context, root int, contents *markdown.FileContents, _ bool) (int, error) {
+	m.i.mu.Lock()
+	defer m.i.mu.Unlock()
 
 	var isroot bool
 
@@ -110,20 +119,17 @@ func (m mockclient) CreatePage(root int, contents *markdown.FileContents, _ bool
 		isroot = false
 	}
 
-	id := m.i.append(root, contents, isroot)
-
-	<-s
-
-	return id, nil
+	return m.i.append(root, contents, isroot), nil
 }
 
-func (m mockclient) DeletePage(pageID int) error {
+func (m mockclient) DeletePage(ctx context.Context, pageID int) error {
 	return nil
 }
 
-func (m mockclient) UpdatePage(pageID int, pageVersion int64, pageContents *markdown.FileContents,
-	originalPage confluence.PageResults) (bool, error) {
-	s <- true // race blocker
+func (m mockclient) UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64, pageContents *markdown.FileContents,
+	originalPage confluence.PageResults) (int, error) {
+	m.i.mu.Lock()
+	defer m.i.mu.Unlock()
 
 	var isroot bool
 
@@ -137,15 +143,45 @@ func (m mockclient) UpdatePage(pageID int, pageVersion int64, pageContents *mark
 
 	_ = m.i.append(pageID, pageContents, isroot)
 
-	<-s
+	return int(pageVersion) + 1, nil
+}
+
+func (m mockclient) FindPage(ctx context.Context, title string, many bool) (*confluence.PageResults, error) {
+	return nil, nil
+}
 
+func (m mockclient) UploadAttachment(ctx context.Context, filename string, id int, index bool, indexid int) (bool, error) {
 	return true, nil
 }
 
-func (m mockclient) FindPage(title string, many bool) (*confluence.PageResults, error) {
+func (m mockclient) GetLabels(ctx context.Context, pageID int) ([]string, error) {
+	return nil, nil
+}
+
+func (m mockclient) AddLabels(ctx context.Context, pageID int, labels []string) error {
+	return nil
+}
+
+func (m mockclient) RemoveLabel(ctx context.Context, pageID int, label string) error {
+	return nil
+}
+
+func (m mockclient) GetProperty(ctx context.Context, pageID int, key string) (*confluence.Property, error) {
+	return nil, nil
+}
+
+func (m mockclient) SetProperty(ctx context.Context, pageID int, key string, value interface{}) error {
+	return nil
+}
+
+func (m mockclient) FindManagedPages(ctx context.Context, pageID int) ([]confluence.Page, error) {
+	return nil, nil
+}
+
+func (m mockclient) ListAttachments(ctx context.Context, pageID int) ([]confluence.Attachment, error) {
 	return nil, nil
 }
 
-func (m mockclient) UploadAttachment(filename string, id int, index bool, indexid int) error {
+func (m mockclient) DeleteAttachment(ctx context.Context, attachmentID string) error {
 	return nil
 }
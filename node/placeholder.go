@@ -0,0 +1,188 @@
+package node
+
+// placeholder - the page of every file is found (or created empty) before any page is rendered
+// so relative links between the pages can be resolved the first time each page is rendered
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// findRootPage method finds the page of the root folder (without creating it) and reads the files renamed
+// and the manifest of the pages synced by the last run, which are kept on it - before the preflight so it can
+// trust the titles the last run gave - a page with the title that the sync does not manage is not the root page
+func (node *Node) findRootPage(root *plannedPage) error {
+	node.syncer.lastSynced = ""
+
+	node.syncer.loadManifestFile()
+
+	root.id = node.syncer.manifestID(root.path)
+
+	if root.id == 0 {
+		found, err := node.findPlannedPage(root, node.masterID)
+		if err != nil {
+			return fmt.Errorf("find root page error: %w", err)
+		}
+
+		if found == nil || !node.syncedPage(*found) {
+			return nil
+		}
+
+		root.id, err = strconv.Atoi(found.ID)
+		if err != nil {
+			return fmt.Errorf("find root page error: %w", err)
+		}
+	}
+
+	root.parentID = node.masterID
+	node.id = root.id
+
+	node.loadRenames() // files renamed since the last sync keep their pages
+	node.loadManifest()
+
+	return nil
+}
+
+// createPlaceholders method finds the page of each planned page and creates an empty page for those not found
+// (under the page of the folder above, or the parent page) and adds their IDs to the tree branches
+func (node *Node) createPlaceholders(pages []*plannedPage) error {
+	root := pages[0]
+
+	if root.id == 0 {
+		err := node.placeholder(root, node.masterID)
+		if err != nil {
+			return fmt.Errorf("placeholder error for the root page: %w", err)
+		}
+
+		node.id = root.id
+	}
+
+	for _, page := range root.children {
+		node.placeholders(page, root.id)
+	}
+
+	for _, page := range pages[1:] {
+		node.placeholders(page, node.masterID)
+	}
+
+	node.syncer.wg.Wait()
+
+	node.syncer.tree.mapSem <- struct{}{}
+
+	defer func() { <-node.syncer.tree.mapSem }()
+
+	walkPages(pages, func(page *plannedPage) {
+		if page.id == 0 {
+			return
+		}
+
+		for _, link := range page.links {
+			node.syncer.tree.branches[link] = strconv.Itoa(page.id)
+		}
+	})
+
+	return nil
+}
+
+// placeholders method finds (or creates) the page then the pages under it - concurrently
+// the pages under a page that could not be found or created are left to be found when they are rendered
+func (node *Node) placeholders(page *plannedPage, parentID int) {
+	node.syncer.wg.Go(node.syncer.runCtx, func() {
+		err := node.placeholder(page, parentID)
+		if err != nil {
+			node.syncer.fileError(page.path, fmt.Errorf("placeholder error for [%s]: %w", node.syncer.relativePath(page.path), err))
+			return
+		}
+
+		for _, child := range page.children {
+			node.placeholders(child, page.id)
+		}
+	}, nil)
+}
+
+// placeholder method sets the ID of the planned page: the page recorded for the file in the manifest,
+// else the page found by findPlannedPage or if there is none an empty page with its title created under the parent page
+// (marked as managed, so it is removed like any other page if its file is gone)
+func (node *Node) placeholder(page *plannedPage, parentID int) error {
+	page.parentID = parentID
+
+	page.id = node.syncer.manifestID(page.path)
+	if page.id != 0 {
+		return nil
+	}
+
+	found, err := node.findPlannedPage(page, parentID)
+	if err != nil {
+		return err
+	}
+
+	if found != nil {
+		page.id, err = strconv.Atoi(found.ID)
+
+		return err
+	}
+
+	contents := &markdown.FileContents{
+		MetaData: map[string]interface{}{
+			"title": page.title,
+		},
+	}
+
+	page.id, err = node.syncer.client.CreatePage(node.syncer.requestCtx, parentID, contents, parentID == 0)
+	if err != nil {
+		return fmt.Errorf("create placeholder page error for title [%s]: %w", page.title, err)
+	}
+
+	log.Printf("created placeholder page [%d] for [%s]", page.id, node.syncer.relativePath(page.path))
+
+	node.syncer.recordPlaceholder(page.id)
+
+	err = node.syncer.client.SetProperty(node.syncer.requestCtx, page.id, confluence.ManagedPropertyKey, node.syncer.newManagedProperty(contents, page.path))
+	if err != nil {
+		log.Printf("managed property error for placeholder page [%d]: %v", page.id, err)
+	}
+
+	return nil
+}
+
+// findPlannedPage method returns the page of the planned page: the page with its title, the page managed for the file
+// under the parent page or the page of the file before it was renamed - nil if none of them is found
+func (node *Node) findPlannedPage(page *plannedPage, parentID int) (*confluence.Page, error) {
+	found, err := node.syncer.client.FindPage(node.syncer.requestCtx, page.title, false)
+	if err != nil {
+		return nil, fmt.Errorf("find page error for title [%s]: %w", page.title, err)
+	}
+
+	lookup := &Node{syncer: node.syncer, masterID: parentID} // finds the pages under the parent page
+
+	managed := node.syncer.newManagedProperty(&markdown.FileContents{}, page.path)
+
+	if found == nil {
+		found, err = lookup.findManagedPage(managed)
+		if err != nil {
+			return nil, fmt.Errorf("find managed page error for title [%s]: %w", page.title, err)
+		}
+	}
+
+	if found == nil {
+		found, err = node.syncer.findRenamedPage(managed)
+		if err != nil {
+			return nil, fmt.Errorf("find renamed page error for title [%s]: %w", page.title, err)
+		}
+	}
+
+	if found == nil || len(found.Results) == 0 {
+		return nil, nil
+	}
+
+	err = node.syncer.checkOwnership(found.Results[0], managed, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("ownership error for title [%s]: %w", page.title, err)
+	}
+
+	return &found.Results[0], nil
+}
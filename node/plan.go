@@ -0,0 +1,676 @@
+package node
+
+// plan - an APIClienter that reads from confluence but records the changes a sync would make
+// instead of making them, so a sync can be previewed before it touches a shared space
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// actions of the changes recorded by a Planner - in the order they are printed
+const (
+	PlanCreate            = "create"
+	PlanUpdate            = "update"
+	PlanMove              = "move"
+	PlanRemove            = "remove"
+	PlanUploadAttachment  = "upload-attachment"
+	PlanReplaceAttachment = "replace-attachment"
+	PlanRemoveAttachment  = "remove-attachment"
+	PlanLabels            = "labels"
+)
+
+var planActions = []string{PlanCreate, PlanUpdate, PlanMove, PlanRemove,
+	PlanUploadAttachment, PlanReplaceAttachment, PlanRemoveAttachment, PlanLabels}
+
+// Change is a change to confluence a sync would make
+type Change struct {
+	Action       string   `json:"action"`
+	PageID       int      `json:"pageId,omitempty"` // negative for pages that would be created
+	Title        string   `json:"title,omitempty"`
+	FromTitle    string   `json:"fromTitle,omitempty"`    // the current title of a page that would be retitled
+	ParentID     int      `json:"parentId,omitempty"`     // the page a page would be created or moved under
+	FromParentID int      `json:"fromParentId,omitempty"` // the page a page would be moved from
+	Diff         string   `json:"diff,omitempty"`         // unified diff of the storage body of an updated page
+	Mode         string   `json:"mode,omitempty"`         // how a page would be removed (trash / purge / archive)
+	File         string   `json:"file,omitempty"`         // the attachment file name
+	AttachmentID string   `json:"attachmentId,omitempty"`
+	Added        []string `json:"added,omitempty"`   // labels that would be added
+	Removed      []string `json:"removed,omitempty"` // labels that would be removed
+}
+
+// Plan is every change a sync would make
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// pageGetter is implemented by the confluence clients - used to read the body of a page to diff it
+type pageGetter interface {
+	GetPage(ctx context.Context, pageID int) (*confluence.Page, error)
+}
+
+// Planner is an APIClienter that reads from confluence with the client it wraps
+// but records the pages it would create, update, move and remove (and their attachments and labels)
+// instead of changing them - pages it would create are given negative IDs
+type Planner struct {
+	client      APIClienter
+	removalMode string
+
+	mu          sync.Mutex
+	nextID      int
+	titles      map[int]string              // titles of the pages read or planned by ID
+	created     map[string]*confluence.Page // pages that would be created by title
+	attachments map[string]Change           // attachments read by ID (for naming removed attachments)
+	changes     map[string]*Change          // the changes by action and page/file - only the latest is kept
+}
+
+// NewPlanner returns a Planner reading from client
+// removalMode is how the client would remove pages (reported with each removal)
+func NewPlanner(client APIClienter, removalMode string) *Planner {
+	if removalMode == "" {
+		removalMode = confluence.RemoveTrash
+	}
+
+	return &Planner{
+		client:      client,
+		removalMode: removalMode,
+		nextID:      -1,
+		titles:      map[int]string{},
+		created:     map[string]*confluence.Page{},
+		attachments: map[string]Change{},
+		changes:     map[string]*Change{},
+	}
+}
+
+// record method stores a change keyed by its action and what it changes
+// replacing any change recorded for the same thing (e.g. the same page updated twice)
+func (p *Planner) record(key string, change Change) {
+	p.changes[change.Action+"|"+key] = &change
+}
+
+// forget method removes the change recorded for the action and key
+func (p *Planner) forget(action, key string) {
+	delete(p.changes, action+"|"+key)
+}
+
+// seen method records the titles of pages read from confluence
+func (p *Planner) seen(pages ...confluence.Page) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for index := range pages {
+		id, err := strconv.Atoi(pages[index].ID)
+		if err == nil {
+			p.titles[id] = pages[index].Title
+		}
+	}
+}
+
+// title method returns the title of a page read or planned, or an empty string if it is not known
+func (p *Planner) title(id int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.titles[id]
+}
+
+// CreatePage method records the page as created and returns a negative page ID for it
+func (p *Planner) CreatePage(ctx context.Context, root int, contents *markdown.FileContents, isroot bool) (int, error) {
+	if contents == nil {
+		return 0, fmt.Errorf("createpage error: contents parameter is nil")
+	}
+
+	title, ok := contents.MetaData["title"].(string)
+	if !ok {
+		return 0, fmt.Errorf("createpage error: title is empty")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if page, exists := p.created[title]; exists {
+		id, _ := strconv.Atoi(page.ID)
+		return id, nil
+	}
+
+	id := p.nextID
+	p.nextID--
+
+	if isroot {
+		root = 0
+	}
+
+	page := &confluence.Page{
+		ID:        strconv.Itoa(id),
+		Type:      "page",
+		Title:     title,
+		Version:   confluence.VersionObj{Number: 1},
+		Ancestors: []confluence.AncestorObj{{ID: root}},
+		Body:      confluence.BodyObj{Storage: confluence.StorageObj{Value: string(contents.Body)}},
+		Metadata:  &confluence.MetadataObj{Properties: map[string]confluence.Property{}},
+	}
+
+	p.created[title] = page
+	p.titles[id] = title
+
+	p.record(title, Change{Action: PlanCreate, PageID: id, Title: title, ParentID: root})
+
+	return id, nil
+}
+
+// createdPage method returns the page that would be created with the ID provided
+func (p *Planner) createdPage(id int) *confluence.Page {
+	for _, page := range p.created {
+		if page.ID == strconv.Itoa(id) {
+			return page
+		}
+	}
+
+	return nil
+}
+
+// UpdatePage method records the changes to the title, body and parent of a page
+// pages that would be created are changed in the plan without recording an update
+// it returns the version the page would be updated to - or 0 if the page would be unchanged
+func (p *Planner) UpdatePage(ctx context.Context, pageID, parentID int, pageVersion int64,
+	contents *markdown.FileContents, originalPage confluence.PageResults) (int, error) {
+	title, _ := contents.MetaData["title"].(string)
+
+	if pageID < 0 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if page := p.createdPage(pageID); page != nil {
+			page.Body.Storage.Value = string(contents.Body)
+		}
+
+		return int(pageVersion) + 1, nil
+	}
+
+	current := confluence.Page{}
+	if len(originalPage.Results) > 0 {
+		current = originalPage.Results[0]
+	}
+
+	if current.Body.Storage.Value == "" {
+		if getter, ok := p.client.(pageGetter); ok {
+			page, err := getter.GetPage(ctx, pageID)
+			if err != nil {
+				return 0, fmt.Errorf("plan update error for page [%d]: %w", pageID, err)
+			}
+
+			current.Body = page.Body
+		}
+	}
+
+	diff, err := bodyDiff(current.Body.Storage.Value, string(contents.Body))
+	if err != nil {
+		return 0, fmt.Errorf("plan update error for page [%d]: %w", pageID, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := strconv.Itoa(pageID)
+
+	// a page is generated more than once a run (e.g. again once relative links can be resolved)
+	// so only the changes from the latest update of the page are kept
+	p.forget(PlanUpdate, key)
+	p.forget(PlanMove, key)
+
+	version := 0
+
+	if diff != "" || (current.Title != "" && current.Title != title) {
+		version = int(pageVersion) + 1
+
+		change := Change{Action: PlanUpdate, PageID: pageID, Title: title, Diff: diff}
+
+		if current.Title != title {
+			change.FromTitle = current.Title
+		}
+
+		p.record(key, change)
+	}
+
+	if len(originalPage.Results) > 0 && parentID != 0 && current.ParentID() != parentID {
+		p.record(key, Change{Action: PlanMove, PageID: pageID, Title: title,
+			ParentID: parentID, FromParentID: current.ParentID()})
+
+		version = int(pageVersion) + 1
+	}
+
+	return version, nil
+}
+
+// bodyDiff function returns a unified diff of the storage body in confluence and the body generated from the repo
+func bodyDiff(before, after string) (string, error) {
+	if before == after {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "confluence",
+		ToFile:   "repo",
+		Context:  3,
+	})
+}
+
+// DeletePage method records the page as removed
+func (p *Planner) DeletePage(ctx context.Context, pageID int) error {
+	title := p.title(pageID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.record(strconv.Itoa(pageID), Change{Action: PlanRemove, PageID: pageID, Title: title, Mode: p.removalMode})
+
+	return nil
+}
+
+// FindPage method finds pages in confluence - and the pages that would be created by title
+// (so they are not created twice) - the children of pages that would be created are not listed
+func (p *Planner) FindPage(ctx context.Context, title string, many bool) (*confluence.PageResults, error) {
+	if many {
+		if id, err := strconv.Atoi(title); err == nil && id < 0 {
+			return nil, nil
+		}
+	} else {
+		p.mu.Lock()
+		page, exists := p.created[title]
+		p.mu.Unlock()
+
+		if exists {
+			return &confluence.PageResults{Results: []confluence.Page{*page}}, nil
+		}
+	}
+
+	results, err := p.client.FindPage(ctx, title, many)
+	if results != nil {
+		p.seen(results.Results...)
+	}
+
+	return results, err
+}
+
+// FindManagedPages method finds the managed pages below a page in confluence
+func (p *Planner) FindManagedPages(ctx context.Context, pageID int) ([]confluence.Page, error) {
+	if pageID < 0 {
+		return nil, nil
+	}
+
+	pages, err := p.client.FindManagedPages(ctx, pageID)
+	p.seen(pages...)
+
+	return pages, err
+}
+
+// UploadAttachment method records the attachment as uploaded - or as replaced if the page
+// has an attachment with the same name but different contents - unchanged attachments are not recorded
+// it returns true if the attachment would be uploaded
+func (p *Planner) UploadAttachment(ctx context.Context, filename string, id int, index bool, indexid int) (bool, error) {
+	pageID := id
+	if index {
+		pageID = indexid
+	}
+
+	name := filepath.Base(filename)
+	action := PlanUploadAttachment
+
+	if pageID > 0 {
+		existing, err := p.ListAttachments(ctx, pageID)
+		if err != nil {
+			return false, fmt.Errorf("plan upload error for page [%d]: %w", pageID, err)
+		}
+
+		hash, err := fileHash(filename)
+		if err != nil {
+			return false, fmt.Errorf("plan upload error for page [%d]: %w", pageID, err)
+		}
+
+		for _, attachment := range existing {
+			if attachment.Title != name {
+				continue
+			}
+
+			if attachment.Hash() == hash {
+				return false, nil
+			}
+
+			action = PlanReplaceAttachment
+		}
+	}
+
+	title := p.title(pageID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.record(strconv.Itoa(pageID)+"|"+name, Change{Action: action, PageID: pageID, Title: title, File: name})
+
+	return true, nil
+}
+
+// fileHash function returns the sha256 of a file (as recorded on attachments uploaded by the tool)
+func fileHash(path string) (string, error) {
+	contents, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(contents)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetLabels method returns the labels of a page in confluence (pages that would be created have none)
+func (p *Planner) GetLabels(ctx context.Context, pageID int) ([]string, error) {
+	if pageID < 0 {
+		return nil, nil
+	}
+
+	return p.client.GetLabels(ctx, pageID)
+}
+
+// AddLabels method records the labels as added to the page
+func (p *Planner) AddLabels(ctx context.Context, pageID int, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	p.labels(pageID, func(change *Change) {
+		change.Added = mergeLabels(change.Added, labels...)
+	})
+
+	return nil
+}
+
+// RemoveLabel method records the label as removed from the page
+func (p *Planner) RemoveLabel(ctx context.Context, pageID int, label string) error {
+	p.labels(pageID, func(change *Change) {
+		change.Removed = mergeLabels(change.Removed, label)
+	})
+
+	return nil
+}
+
+// labels method applies update to the label change recorded for a page
+func (p *Planner) labels(pageID int, update func(change *Change)) {
+	title := p.title(pageID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := PlanLabels + "|" + strconv.Itoa(pageID)
+
+	change, ok := p.changes[key]
+	if !ok {
+		change = &Change{Action: PlanLabels, PageID: pageID, Title: title}
+		p.changes[key] = change
+	}
+
+	update(change)
+}
+
+// mergeLabels function adds labels to a sorted list of labels without duplicates
+func mergeLabels(labels []string, add ...string) []string {
+	for index := range add {
+		found := false
+
+		for index2 := range labels {
+			if labels[index2] == add[index] {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			labels = append(labels, add[index])
+		}
+	}
+
+	sort.Strings(labels)
+
+	return labels
+}
+
+// GetProperty method returns a content property of a page in confluence
+// or of a page that would be created
+func (p *Planner) GetProperty(ctx context.Context, pageID int, key string) (*confluence.Property, error) {
+	if pageID < 0 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if page := p.createdPage(pageID); page != nil {
+			if property, ok := page.Metadata.Properties[key]; ok {
+				return &property, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	return p.client.GetProperty(ctx, pageID, key)
+}
+
+// SetProperty method stores the property on a page that would be created (so it is found as managed)
+// the properties are the tool's own bookkeeping so they are not recorded as changes
+func (p *Planner) SetProperty(ctx context.Context, pageID int, key string, value interface{}) error {
+	if pageID >= 0 {
+		return nil
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("setproperty json marshal error: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if page := p.createdPage(pageID); page != nil {
+		page.Metadata.Properties[key] = confluence.Property{Key: key, Value: valueJSON}
+	}
+
+	return nil
+}
+
+// ListAttachments method returns the attachments of a page in confluence
+// (pages that would be created have none)
+func (p *Planner) ListAttachments(ctx context.Context, pageID int) ([]confluence.Attachment, error) {
+	if pageID < 0 {
+		return nil, nil
+	}
+
+	attachments, err := p.client.ListAttachments(ctx, pageID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for index := range attachments {
+		p.attachments[attachments[index].ID] = Change{PageID: pageID, Title: p.titles[pageID], File: attachments[index].Title}
+	}
+
+	return attachments, err
+}
+
+// DeleteAttachment method records the attachment as removed
+func (p *Planner) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change := p.attachments[attachmentID]
+	change.Action = PlanRemoveAttachment
+	change.AttachmentID = attachmentID
+
+	p.record(attachmentID, change)
+
+	return nil
+}
+
+// Plan method returns the changes recorded - sorted by action and then page title
+func (p *Planner) Plan() Plan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rank := map[string]int{}
+
+	for index := range planActions {
+		rank[planActions[index]] = index
+	}
+
+	plan := Plan{Changes: []Change{}}
+
+	for _, change := range p.changes {
+		plan.Changes = append(plan.Changes, *change)
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+
+		if rank[a.Action] != rank[b.Action] {
+			return rank[a.Action] < rank[b.Action]
+		}
+
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+
+		return a.File < b.File
+	})
+
+	return plan
+}
+
+// Print method writes the plan in a readable form - one line per change (followed by the diff of updated pages)
+// and a summary of the number of changes
+func (p *Planner) Print(w io.Writer) error {
+	plan := p.Plan()
+
+	builder := &strings.Builder{}
+
+	if len(plan.Changes) == 0 {
+		builder.WriteString("No changes. Confluence is up to date with the repo.\n")
+
+		_, err := io.WriteString(w, builder.String())
+
+		return err
+	}
+
+	builder.WriteString("markdown-to-confluence would make the following changes:\n\n")
+
+	counts := map[string]int{}
+
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+
+		builder.WriteString("  " + p.describe(change) + "\n")
+
+		if change.Diff != "" {
+			for _, line := range strings.SplitAfter(strings.TrimRight(change.Diff, "\n"), "\n") {
+				builder.WriteString("      " + line)
+			}
+
+			builder.WriteString("\n\n")
+		}
+	}
+
+	fmt.Fprintf(builder, "\nPlan: %d to create, %d to update, %d to move, %d to remove, %d attachment(s) to change, "+
+		"%d page(s) with label changes.\n",
+		counts[PlanCreate], counts[PlanUpdate], counts[PlanMove], counts[PlanRemove],
+		counts[PlanUploadAttachment]+counts[PlanReplaceAttachment]+counts[PlanRemoveAttachment], counts[PlanLabels])
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// describe method returns a one line description of a change
+func (p *Planner) describe(change Change) string {
+	page := p.pageName(change.PageID, change.Title)
+
+	switch change.Action {
+	case PlanCreate:
+		return fmt.Sprintf("+ create page %s under %s", page, p.pageName(change.ParentID, ""))
+	case PlanUpdate:
+		if change.FromTitle != "" {
+			return fmt.Sprintf("~ update page %s (retitled from %q)", page, change.FromTitle)
+		}
+
+		return fmt.Sprintf("~ update page %s", page)
+	case PlanMove:
+		return fmt.Sprintf("> move page %s from %s to %s", page,
+			p.pageName(change.FromParentID, ""), p.pageName(change.ParentID, ""))
+	case PlanRemove:
+		return fmt.Sprintf("- remove page %s (%s)", page, change.Mode)
+	case PlanUploadAttachment:
+		return fmt.Sprintf("+ upload attachment %q to page %s", change.File, page)
+	case PlanReplaceAttachment:
+		return fmt.Sprintf("~ upload a new version of attachment %q to page %s", change.File, page)
+	case PlanRemoveAttachment:
+		return fmt.Sprintf("- remove attachment %q [%s] from page %s", change.File, change.AttachmentID, page)
+	case PlanLabels:
+		labels := []string{}
+
+		for _, label := range change.Added {
+			labels = append(labels, "+"+label)
+		}
+
+		for _, label := range change.Removed {
+			labels = append(labels, "-"+label)
+		}
+
+		return fmt.Sprintf("~ change labels on page %s: %s", page, strings.Join(labels, " "))
+	}
+
+	return change.Action
+}
+
+// pageName method returns the title and ID of a page for describing a change
+// pages that would be created are shown as (new)
+func (p *Planner) pageName(id int, title string) string {
+	if title == "" {
+		title = p.title(id)
+	}
+
+	switch {
+	case id == 0:
+		return "the top of the space"
+	case id < 0:
+		return fmt.Sprintf("%q (new)", title)
+	case title == "":
+		return fmt.Sprintf("[%d]", id)
+	}
+
+	return fmt.Sprintf("%q [%d]", title, id)
+}
+
+// WriteJSON method writes the plan as json to the file path provided
+func (p *Planner) WriteJSON(path string) error {
+	planJSON, err := json.MarshalIndent(p.Plan(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("plan json marshal error: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Clean(path), append(planJSON, '\n'), 0o600)
+	if err != nil {
+		return fmt.Errorf("write plan error: %w", err)
+	}
+
+	return nil
+}
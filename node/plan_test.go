@@ -0,0 +1,128 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	markdown "github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// planSync function runs a sync of testfolder with a Planner wrapping client
+// and checks that nothing but reads were sent to the server
+func planSync(t *testing.T, server *confluencetest.Server, client APIClienter, masterID int) *Planner {
+	planner := NewPlanner(client, confluence.RemoveTrash)
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID}, planner)
+
+	before := server.Pages()
+	requests := len(server.Requests())
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	assert.Equal(t, before, server.Pages())
+
+	for _, request := range server.Requests()[requests:] {
+		assert.True(t, strings.HasPrefix(request, "GET "), request)
+	}
+
+	return planner
+}
+
+func TestPlanner_NewPages(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	planner := planSync(t, server, server.Client(), masterID)
+
+	actions := map[string][]string{}
+
+	for _, change := range planner.Plan().Changes {
+		actions[change.Action] = append(actions[change.Action], change.Title+"|"+change.File)
+	}
+
+	assert.Equal(t, map[string][]string{
+		PlanCreate: {
+			"INDEX readme (testfolder)|",
+			"a deeply nested readme file (testfolder/file/downhere)|",
+			"file within readme! (testfolder/file/downhere)|",
+		},
+		PlanUploadAttachment: {"a deeply nested readme file (testfolder/file/downhere)|picture.jpg"},
+	}, actions)
+
+	output := &bytes.Buffer{}
+	assert.NoError(t, planner.Print(output))
+	assert.Contains(t, output.String(), `+ create page "file within readme! (testfolder/file/downhere)" (new) under `+
+		`"a deeply nested readme file (testfolder/file/downhere)" (new)`)
+	assert.Contains(t, output.String(), "Plan: 3 to create, 0 to update, 0 to move, 0 to remove, 1 attachment(s) to change")
+}
+
+func TestPlanner_ExistingPages(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	ctx := context.Background()
+	client := server.Client()
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID}, client)
+	assert.True(t, syncer.Start(ctx))
+	assert.NoError(t, syncer.Delete())
+
+	// change confluence by hand so the next sync has something to do - the page is retitled
+	// so the page is found by its managed property and is planned as retitled rather than removed
+	hello, _ := server.PageByTitle("file within readme! (testfolder/file/downhere)")
+	found, err := client.FindPage(ctx, "file within readme! (testfolder/file/downhere)", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.UpdatePage(ctx, hello.ID, 0, int64(hello.Version), &markdown.FileContents{
+		MetaData: map[string]interface{}{"title": "an old title"},
+		Body:     []byte("<p>edited in confluence</p>"),
+	}, *found)
+	assert.NoError(t, err)
+
+	// stale is recorded as applied by the tool so it is removed - by-hand was added in confluence so it is kept
+	assert.NoError(t, client.AddLabels(ctx, hello.ID, []string{"stale", "by-hand"}))
+
+	managed, _ := found.Results[0].Managed()
+	managed.Labels = []string{"stale"}
+	assert.NoError(t, client.SetProperty(ctx, hello.ID, confluence.ManagedPropertyKey, managed))
+
+	folder, _ := server.PageByTitle("INDEX readme (testfolder)")
+	goneID := server.AddPage(folder.ID, "gone", "")
+	assert.NoError(t, client.SetProperty(ctx, goneID, confluence.ManagedPropertyKey,
+		confluence.ManagedProperty{Repo: syncer.repoName(), Path: "gone.md"}))
+
+	planner := planSync(t, server, client, masterID)
+
+	changes := map[string]Change{}
+
+	for _, change := range planner.Plan().Changes {
+		changes[change.Action] = change
+	}
+
+	assert.Len(t, planner.Plan().Changes, 3)
+	assert.Equal(t, hello.ID, changes[PlanUpdate].PageID)
+	assert.Equal(t, "an old title", changes[PlanUpdate].FromTitle)
+	assert.Contains(t, changes[PlanUpdate].Diff, "-<p>edited in confluence</p>")
+	assert.Contains(t, changes[PlanUpdate].Diff, "+<h1>file within readme!</h1>")
+	assert.Equal(t, Change{Action: PlanRemove, PageID: goneID, Title: "gone", Mode: confluence.RemoveTrash},
+		changes[PlanRemove])
+	assert.Equal(t, []string{"stale"}, changes[PlanLabels].Removed)
+
+	output := &bytes.Buffer{}
+	assert.NoError(t, planner.Print(output))
+	assert.Contains(t, output.String(), `- remove page "gone" [`)
+	assert.Contains(t, output.String(), `(retitled from "an old title")`)
+	assert.NotContains(t, output.String(), `- remove page "an old title"`)
+	assert.Contains(t, output.String(), "      -<p>edited in confluence</p>\n")
+}
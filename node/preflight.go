@@ -0,0 +1,310 @@
+package node
+
+// preflight - the pages a sync will make and their titles, checked before any page is changed
+// as confluence titles are unique in a space and pages are found by their title
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+	"github.com/xiatechs/markdown-to-confluence/swagger"
+)
+
+// plannedPage is a page the sync makes for a file (or folder) of the repo
+type plannedPage struct {
+	path     string         // the file the page is made from - or the folder for a generic folder page
+	links    []string       // the paths relative links to the page are resolved with (the tree branches)
+	title    string         // the title the title template (or the preflight) gives the page
+	children []*plannedPage // the pages made under the page
+	id       int            // the confluence page ID once the page has been found or created
+	parentID int            // the confluence page ID of the page it is made under
+}
+
+// titleConflict is a page title the sync would give to more than one file (or folder) of the repo
+// or to a file when a page it does not manage already has the title
+type titleConflict struct {
+	title  string
+	paths  []string // the files (or folders) given the title from the root of the repo
+	pageID string   // the page not managed by the sync that has the title - empty if there is none
+}
+
+// String method describes the conflict for the preflight report
+func (c titleConflict) String() string {
+	if c.pageID != "" {
+		return fmt.Sprintf("title [%s] of [%s] is the title of page [%s] which this sync does not manage",
+			c.title, strings.Join(c.paths, "], ["), c.pageID)
+	}
+
+	return fmt.Sprintf("title [%s] would be given to [%s]", c.title, strings.Join(c.paths, "] and ["))
+}
+
+// preflight method checks the titles of the pages the sync plans to make: that no two pages would get the same title
+// and that no page the sync does not manage already has one of them (the sync would update that page instead,
+// or fail to create its own) - what happens to the duplicate titles is set by the repo config: the run is stopped
+// before any page is changed (the default), the duplicate titles are given the repo and path of their file
+// (and the planned pages are retitled), or they are only reported
+func (node *Node) preflight(pages []*plannedPage) error {
+	titles := map[string][]string{} // title: paths
+
+	walkPages(pages, func(page *plannedPage) {
+		titles[page.title] = append(titles[page.title], node.syncer.relativePath(page.path))
+	})
+
+	conflicts, err := node.titleConflicts(titles)
+	if err != nil {
+		return fmt.Errorf("preflight error: %w", err)
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	report := make([]string, 0, len(conflicts))
+	for index := range conflicts {
+		report = append(report, conflicts[index].String())
+	}
+
+	switch node.syncer.repoConfig.DuplicateTitles {
+	case config.DuplicatesWarn:
+		log.Printf("preflight warning - duplicate page titles:\n  %s", strings.Join(report, "\n  "))
+
+		return nil
+	case config.DuplicatesSuffix:
+		err = node.disambiguate(conflicts)
+		if err != nil {
+			return err
+		}
+
+		walkPages(pages, func(page *plannedPage) {
+			if title, ok := node.syncer.retitled[node.syncer.relativePath(page.path)]; ok {
+				page.title = title
+			}
+		})
+
+		return nil
+	default:
+		return fmt.Errorf("preflight error - duplicate page titles (set duplicateTitles to %s or %s to carry on):\n  %s",
+			config.DuplicatesSuffix, config.DuplicatesWarn, strings.Join(report, "\n  "))
+	}
+}
+
+// disambiguate method gives the files with a duplicate title the title followed by the repo and their path
+// and checks no page the sync does not manage already has one of the new titles
+func (node *Node) disambiguate(conflicts []titleConflict) error {
+	titles := map[string][]string{}
+
+	for _, conflict := range conflicts {
+		for _, path := range conflict.paths {
+			title := fmt.Sprintf("%s [%s %s]", conflict.title, node.syncer.repoName(), path)
+
+			node.syncer.retitled[path] = title
+			titles[title] = append(titles[title], path)
+
+			log.Printf("preflight - the page of [%s] is titled [%s] as [%s] is a duplicate title", path, title, conflict.title)
+		}
+	}
+
+	remaining, err := node.titleConflicts(titles)
+	if err != nil {
+		return fmt.Errorf("preflight error: %w", err)
+	}
+
+	if len(remaining) > 0 {
+		return fmt.Errorf("preflight error - duplicate page titles: %s", remaining[0])
+	}
+
+	return nil
+}
+
+// titleConflicts method returns the titles (sorted) given to more than one path
+// or already used by a page the sync does not manage
+func (node *Node) titleConflicts(titles map[string][]string) ([]titleConflict, error) {
+	sorted := make([]string, 0, len(titles))
+	for title := range titles {
+		sorted = append(sorted, title)
+	}
+
+	sort.Strings(sorted)
+
+	var conflicts []titleConflict
+
+	for _, title := range sorted {
+		conflict := titleConflict{title: title, paths: titles[title]}
+
+		if len(conflict.paths) == 1 && node.syncer.syncedTitle(conflict.paths[0], title) {
+			continue // the page has the title the last run gave it - so no other page can have it
+		}
+
+		found, err := node.syncer.client.FindPage(node.syncer.requestCtx, title, false)
+		if err != nil {
+			return nil, fmt.Errorf("find page error for title [%s]: %w", title, err)
+		}
+
+		if found != nil && len(found.Results) > 0 && !node.syncedPage(found.Results[0]) {
+			conflict.pageID = found.Results[0].ID
+		}
+
+		if len(conflict.paths) > 1 || conflict.pageID != "" {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// syncedTitle method returns true if the last run gave the page of the file (path from the root of the repo) the title
+func (syncer *Syncer) syncedTitle(path, title string) bool {
+	synced, ok := syncer.manifest[syncer.managedPath(filepath.Join(syncer.projectRoot, path))]
+
+	return ok && synced.ID != 0 && synced.Title == title
+}
+
+// syncedPage method returns true if the page is one the sync updates rather than a page that
+// happens to have the same title - it is managed for this repo, or not managed by any repo and under the parent page
+func (node *Node) syncedPage(page confluence.Page) bool {
+	if managed, ok := page.Managed(); ok {
+		return managed.Repo == node.syncer.repoName()
+	}
+
+	if node.masterID == 0 {
+		return false
+	}
+
+	for _, ancestor := range page.Ancestors {
+		if ancestor.ID == node.masterID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// planPages method returns the pages the sync makes for the folder as generateMaster makes them:
+// a page for the folder (its index file or a generic folder page) if it has page or image files in it
+// (the root folder always has a page) with a page for each of its other page files and the pages of every
+// sub folder the repo config publishes under it - the pages of the sub folders of a folder without a page
+// are returned instead, as they are made under the page of the folder above (or the parent page for the root folder)
+func (node *Node) planPages(folder string, root bool) ([]*plannedPage, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	folderNode := &Node{syncer: node.syncer, path: folder}
+
+	var (
+		alive      bool
+		files      []string
+		subFolders []string
+	)
+
+	for _, entry := range entries {
+		fpath := filepath.Join(folder, entry.Name())
+
+		switch {
+		case entry.IsDir():
+			if node.syncer.repoConfig.Folder(fpath) {
+				subFolders = append(subFolders, fpath)
+			}
+		case node.syncer.repoConfig.Page(fpath):
+			alive = true
+
+			if !strings.EqualFold(entry.Name(), indexName) {
+				files = append(files, fpath)
+			}
+		case node.syncer.repoConfig.Image(fpath):
+			alive = true
+		}
+	}
+
+	var subPages []*plannedPage
+
+	for _, subFolder := range subFolders {
+		pages, err := node.planPages(subFolder, false)
+		if err != nil {
+			return nil, err
+		}
+
+		subPages = append(subPages, pages...)
+	}
+
+	if !alive && !root {
+		return subPages, nil
+	}
+
+	dir, _ := folderNode.generateTitles()
+
+	title, fpath := folderNode.folderTitle(dir)
+
+	page, err := folderNode.planPage(title, fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	if fpath != folder { // links to the folder (e.g. for its images) find the page of its index file
+		page.links = append(page.links, folder)
+	}
+
+	for _, fpath := range files {
+		contents, err := os.ReadFile(filepath.Clean(fpath))
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := folderNode.planPage(sourceTitle(fpath, contents), fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		page.children = append(page.children, child)
+	}
+
+	if !alive { // the root folder has a page even without files but its sub folders are not made under it
+		return append([]*plannedPage{page}, subPages...), nil
+	}
+
+	page.children = append(page.children, subPages...)
+
+	return []*plannedPage{page}, nil
+}
+
+// planPage method returns the planned page of a file (or folder) with the title the title template gives it
+func (node *Node) planPage(title, fpath string) (*plannedPage, error) {
+	title, err := node.pageTitle(title, fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plannedPage{path: fpath, links: []string{fpath}, title: title}, nil
+}
+
+// walkPages function calls fn for every page and the pages under it (each page before the pages under it)
+func walkPages(pages []*plannedPage, fn func(page *plannedPage)) {
+	for _, page := range pages {
+		fn(page)
+		walkPages(page.children, fn)
+	}
+}
+
+// sourceTitle function returns the title the markdown (or swagger) parser gives a file before the title template
+// (a readme.md is given the name of its folder if it has no title)
+func sourceTitle(fpath string, contents []byte) string {
+	fileName := filepath.Base(fpath)
+
+	if isSwagger(fileName) {
+		return swagger.PageTitle(contents, fileName)
+	}
+
+	if strings.EqualFold(fileName, indexName) {
+		fileName = filepath.Base(filepath.Dir(fpath))
+	}
+
+	return markdown.PageTitle(contents, fileName)
+}
@@ -0,0 +1,62 @@
+package node
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+)
+
+// TestPreflight syncs a repo where two files have the same title and a file has the title of a page
+// outside the parent page - the run fails before changing anything unless the titles are disambiguated
+func TestPreflight(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "org/repo")
+
+	folder := filepath.Join(t.TempDir(), "repo")
+
+	for path, contents := range map[string]string{
+		"readme.md":     "# Home\n",
+		"a.md":          "# Setup\n",
+		"b.md":          "---\ntitle: Setup\n---\n",
+		"c.md":          "# Taken\n",
+		"docs/guide.md": "# Guide\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(folder, filepath.Dir(path)), 0o700))
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, path), []byte(contents), 0o600))
+	}
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+	takenID := server.AddPage(0, "Taken", "another team's page")
+
+	options := Options{ProjectPath: folder, ParentID: masterID, TitleTemplate: "{{.Title}}"}
+
+	assert.False(t, NewSyncer(options, server.Client()).Start(context.Background()))
+	assert.Len(t, server.Pages(), 2)
+
+	options.DuplicateTitles = config.DuplicatesSuffix
+
+	syncer := NewSyncer(options, server.Client())
+
+	for run := 1; run <= 2; run++ { // the second run finds the pages with the titles the first run gave them
+		assert.True(t, syncer.Start(context.Background()), "run %d", run)
+		assert.NoError(t, syncer.Delete())
+
+		assert.Equal(t, "Home\n"+
+			"  Setup [org/repo a.md]\n"+
+			"  Setup [org/repo b.md]\n"+
+			"  Taken [org/repo c.md]\n"+
+			"  docs\n"+
+			"    Guide\n", server.Tree(masterID), "run %d", run)
+		assert.Len(t, server.Pages(), 8, "run %d", run)
+	}
+
+	taken, _ := server.Page(takenID)
+	assert.Equal(t, 1, taken.Version)
+}
@@ -4,7 +4,6 @@ package node
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -46,74 +45,85 @@ func (node *Node) processMarkDownIndex(path string) (*markdown.FileContents, err
 			abs, path, err)
 	}
 
-	mapSem <- struct{}{}
+	node.syncer.tree.mapSem <- struct{}{}
 
-	parsedContents, err := markdown.ParseMarkdown(func() int {
+	parsedContents, err := node.syncer.options.Markdown.ParseMarkdown(func() int {
 		if node.root == nil {
 			return 0
 		}
 
 		return node.root.id
 	}(), contents, node.indexPage,
-		node.treeLink.branches, node.path, abs, node.indexName)
+		node.syncer.tree.branches, node.path, abs, node.indexName)
 	if err != nil {
-		<-mapSem
+		<-node.syncer.tree.mapSem
 
 		return nil, fmt.Errorf("absolute path [%s] - file [%s] - parse markdown error: %w",
 			abs, path, err)
 	}
 
-	<-mapSem
+	<-node.syncer.tree.mapSem
 
-	parsedContents.MetaData["title"] = parsedContents.MetaData["title"].(string) + " (" + abs + ")"
+	parsedContents.MetaData["title"], err = node.pageTitle(parsedContents.MetaData["title"].(string), path)
+	if err != nil {
+		return nil, fmt.Errorf("absolute path [%s] - file [%s] - page title error: %w", abs, path, err)
+	}
 
 	return parsedContents, nil
 }
 
 // processFilesDown method takes in file contents
 // and parses the markdown file before calling
-// checkConfluencePages method
+// checkConfluencePages method - unless the file is unchanged since the last sync
 func (node *Node) processFilesDown(path, fileName string) error {
 	_, abs := node.generateTitles()
 
+	if page, ok := node.syncer.unchangedPage(path); ok {
+		node.keepPage(page)
+		return nil
+	}
+
 	contents, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
 		return fmt.Errorf("absolute path [%s] - file [%s] - read file error: %w",
 			abs, path, err)
 	}
 
-	mapSem <- struct{}{}
+	node.syncer.tree.mapSem <- struct{}{}
 
 	var parsedContents *markdown.FileContents
-	if strings.HasSuffix(fileName, ".md") {
-		parsedContents, err = markdown.ParseMarkdown(func() int {
+	if isSwagger(fileName) {
+		parsedContents, err = swagger.ParseSwagger(node.syncer.options.Markdown, func() int {
 			if node.root == nil {
 				return 0
 			}
 
 			return node.root.id
 		}(), contents, node.indexPage,
-			node.treeLink.branches, node.path, abs, fileName)
-	} else if strings.HasSuffix(fileName, ".swagger.json") {
-		parsedContents, err = swagger.ParseSwagger(func() int {
+			node.syncer.tree.branches, node.path, abs, fileName)
+	} else { // every other page file type the repo config publishes is markdown
+		parsedContents, err = node.syncer.options.Markdown.ParseMarkdown(func() int {
 			if node.root == nil {
 				return 0
 			}
 
 			return node.root.id
 		}(), contents, node.indexPage,
-			node.treeLink.branches, node.path, abs, fileName)
+			node.syncer.tree.branches, node.path, abs, fileName)
 	}
 
 	if err != nil {
-		<-mapSem
+		<-node.syncer.tree.mapSem
 		return fmt.Errorf("absolute path [%s] - file [%s] - parse file error: %w",
 			abs, path, err)
 	}
 
-	<-mapSem
+	<-node.syncer.tree.mapSem
 
-	parsedContents.MetaData["title"] = parsedContents.MetaData["title"].(string) + " (" + abs + ")"
+	parsedContents.MetaData["title"], err = node.pageTitle(parsedContents.MetaData["title"].(string), path)
+	if err != nil {
+		return fmt.Errorf("absolute path [%s] - file [%s] - page title error: %w", abs, path, err)
+	}
 
 	err = node.checkConfluencePages(parsedContents, path)
 	if err != nil {
@@ -126,12 +136,38 @@ func (node *Node) processFilesDown(path, fileName string) error {
 
 // uploadFile method takes in file and
 // uploads the file to a page by parent page ID (node.root.id)
+// the upload is recorded so the attachment is kept when orphaned attachments are deleted
+// images unchanged since the last sync are only recorded
 func (node *Node) uploadFile(path string, isIndexPage bool) {
 	_, abs := node.generateTitles()
 
-	err := nodeAPIClient.UploadAttachment(filepath.Clean(path), node.root.id, isIndexPage, node.id)
+	pageID := node.root.id
+	if isIndexPage {
+		pageID = node.id
+	}
+
+	node.syncer.tree.addAttachment(pageID, filepath.Base(path))
+
+	if node.syncer.unchangedImage(path, pageID) {
+		node.syncer.recordPage(path, ResultUnchanged, filepath.Base(path), pageID)
+
+		return
+	}
+
+	uploaded, err := node.syncer.client.UploadAttachment(node.syncer.requestCtx, filepath.Clean(path), node.root.id,
+		isIndexPage, node.id)
 	if err != nil {
-		log.Printf("absolute path [%s] - local path [%s] - file upload error: %v",
-			path, abs, err)
+		node.syncer.fileError(path, fmt.Errorf("absolute path [%s] - local path [%s] - file upload error: %w",
+			path, abs, err))
+
+		return
 	}
+
+	if !uploaded { // the page already has an identical attachment
+		node.syncer.recordPage(path, ResultUnchanged, filepath.Base(path), pageID)
+
+		return
+	}
+
+	node.syncer.recordPage(path, ResultUpdated, filepath.Base(path), pageID)
 }
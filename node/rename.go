@@ -0,0 +1,184 @@
+package node
+
+// rename - methods for recognising files renamed or moved since the last sync
+// so that their existing pages are retitled and moved instead of being recreated
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+// gitHead function returns the commit checked out in dir
+func gitHead(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse error: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitRenames function returns the files renamed in dir between commit since and HEAD
+// as a map of new path -> old path (relative to dir)
+func gitRenames(dir, since string) (map[string]string, error) {
+	out, err := exec.Command("git", "-C", dir, "diff", "--find-renames", "--name-status", "-z",
+		"--relative", since, "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff error: %w", err)
+	}
+
+	return parseRenames(out), nil
+}
+
+// parseRenames function reads the output of git diff --name-status -z
+// and returns the renamed files as a map of new path -> old path
+func parseRenames(out []byte) map[string]string {
+	fields := bytes.Split(bytes.TrimRight(out, "\x00"), []byte{0})
+
+	found := map[string]string{}
+
+	for index := 0; index < len(fields); index++ {
+		status := string(fields[index])
+
+		if !strings.HasPrefix(status, "R") && !strings.HasPrefix(status, "C") {
+			index++ // other statuses are followed by a single path
+
+			continue
+		}
+
+		if index+2 >= len(fields) {
+			break
+		}
+
+		if strings.HasPrefix(status, "R") {
+			found[string(fields[index+2])] = string(fields[index+1])
+		}
+
+		index += 2
+	}
+
+	return found
+}
+
+// syncedCommit method returns the last commit of this repo synced to the node's page
+func (node *Node) syncedCommit() (string, error) {
+	property, err := node.syncer.client.GetProperty(node.syncer.requestCtx, node.id, confluence.SyncPropertyKey)
+	if err != nil || property == nil {
+		return "", err
+	}
+
+	synced := confluence.SyncProperty{}
+
+	err = json.Unmarshal(property.Value, &synced)
+	if err != nil {
+		return "", fmt.Errorf("sync property json unmarshal error: %w", err)
+	}
+
+	if synced.Repo != node.syncer.repoName() {
+		return "", nil
+	}
+
+	return synced.Commit, nil
+}
+
+// loadRenames method finds the files renamed since the last commit synced to the node's page
+// so checkConfluencePages can find the page created for the file under its old path
+func (node *Node) loadRenames() {
+	if node.syncer.client == nil || node.id == 0 {
+		return
+	}
+
+	node.syncer.renameRootID = node.id
+
+	since, err := node.syncedCommit()
+	if err != nil {
+		log.Printf("could not read the last synced commit - renamed files will get new pages: %v", err)
+		return
+	}
+
+	node.syncer.lastSynced = since
+
+	if since == "" {
+		log.Println("no synced commit recorded yet - renamed files will get new pages")
+		return
+	}
+
+	found, err := gitRenames(node.syncer.projectRoot, since)
+	if err != nil {
+		log.Printf("could not find renamed files since [%s] - renamed files will get new pages: %v", since, err)
+		return
+	}
+
+	for newPath, oldPath := range found {
+		newPath = node.syncer.managedPath(filepath.Join(node.syncer.projectRoot, newPath))
+		oldPath = node.syncer.managedPath(filepath.Join(node.syncer.projectRoot, oldPath))
+
+		log.Printf("[%s] was renamed to [%s] since the last sync", oldPath, newPath)
+
+		node.syncer.renames[newPath] = oldPath
+	}
+}
+
+// renamedFrom method returns the managed path a file had before it was renamed
+func (syncer *Syncer) renamedFrom(path string) (string, bool) {
+	old, ok := syncer.renames[path]
+
+	return old, ok
+}
+
+// findRenamedPage method returns the page managed for the file before it was renamed
+// or nil if the file was not renamed (or the page no longer exists)
+// the managed pages are listed once - if that fails the error is returned for every renamed file
+func (syncer *Syncer) findRenamedPage(want confluence.ManagedProperty) (*confluence.PageResults, error) {
+	old, ok := syncer.renamedFrom(want.Path)
+	if !ok || syncer.renameRootID == 0 {
+		return nil, nil
+	}
+
+	syncer.managedOnce.Do(func() {
+		syncer.managedPages, syncer.managedErr = syncer.client.FindManagedPages(syncer.requestCtx, syncer.renameRootID)
+	})
+
+	if syncer.managedErr != nil {
+		return nil, syncer.managedErr
+	}
+
+	for index := range syncer.managedPages {
+		managed, ok := syncer.managedPages[index].Managed()
+		if ok && managed.Repo == want.Repo && managed.Path == old {
+			log.Printf("found page [%s] for [%s] under its old path [%s]", syncer.managedPages[index].ID, want.Path, old)
+
+			return &confluence.PageResults{Results: []confluence.Page{syncer.managedPages[index]}}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// MarkSynced method records the commit checked out in the project as synced on the root page of the repo
+// so the next run can find the files renamed since
+func (syncer *Syncer) MarkSynced() error {
+	if syncer.client == nil || syncer.root == nil || syncer.root.id == 0 {
+		return nil
+	}
+
+	head, err := gitHead(syncer.projectRoot)
+	if err != nil {
+		return fmt.Errorf("mark synced error: %w", err)
+	}
+
+	err = syncer.client.SetProperty(syncer.requestCtx, syncer.root.id, confluence.SyncPropertyKey,
+		confluence.SyncProperty{Repo: syncer.repoName(), Commit: head})
+	if err != nil {
+		return fmt.Errorf("mark synced error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package node
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+func TestParseRenames(t *testing.T) {
+	out := []byte("M\x00docs/readme.md\x00R087\x00docs/setup.md\x00docs/installation.md\x00" +
+		"A\x00docs/new.md\x00C100\x00docs/a.md\x00docs/b.md\x00D\x00docs/old.md\x00")
+
+	assert.Equal(t, map[string]string{"docs/installation.md": "docs/setup.md"}, parseRenames(out))
+	assert.Empty(t, parseRenames(nil))
+}
+
+func TestFindRenamedPage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := NewMockAPIClienter(mockCtrl)
+
+	old := managedPage(t, confluence.ManagedProperty{Repo: "org/repo", Path: "docs/setup.md"})
+	other := managedPage(t, confluence.ManagedProperty{Repo: "org/repo", Path: "docs/other.md"})
+
+	mock.EXPECT().FindManagedPages(gomock.Any(), 3).Return([]confluence.Page{other, old}, nil).Times(1)
+
+	syncer := NewSyncer(Options{}, mock)
+	syncer.renames = map[string]string{"docs/installation.md": "docs/setup.md"}
+	syncer.renameRootID = 3
+
+	want := confluence.ManagedProperty{Repo: "org/repo", Path: "docs/installation.md"}
+
+	found, err := syncer.findRenamedPage(want)
+	assert.NoError(t, err)
+	assert.Equal(t, &confluence.PageResults{Results: []confluence.Page{old}}, found)
+	assert.NoError(t, syncer.checkOwnership(old, want, 0))
+	assert.Error(t, syncer.checkOwnership(other, want, 0))
+
+	found, err = syncer.findRenamedPage(confluence.ManagedProperty{Repo: "org/repo", Path: "docs/new.md"})
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+// TestFindRenamedPage_ListError checks the error listing the managed pages is returned
+// for every renamed file - not only the first one looked up
+func TestFindRenamedPage_ListError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mock := NewMockAPIClienter(mockCtrl)
+
+	listErr := errors.New("list error")
+
+	mock.EXPECT().FindManagedPages(gomock.Any(), 3).Return(nil, listErr).Times(1)
+
+	syncer := NewSyncer(Options{}, mock)
+	syncer.renames = map[string]string{"docs/installation.md": "docs/setup.md", "docs/usage.md": "docs/use.md"}
+	syncer.renameRootID = 3
+
+	found, err := syncer.findRenamedPage(confluence.ManagedProperty{Repo: "org/repo", Path: "docs/installation.md"})
+	assert.ErrorIs(t, err, listErr)
+	assert.Nil(t, found)
+
+	found, err = syncer.findRenamedPage(confluence.ManagedProperty{Repo: "org/repo", Path: "docs/usage.md"})
+	assert.ErrorIs(t, err, listErr)
+	assert.Nil(t, found)
+}
@@ -0,0 +1,260 @@
+package node
+
+// report - what a run did with each file of the repo (and each page it removed)
+// so a run that only partly succeeded can be told apart from one that succeeded
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+)
+
+// results of the files in a Report - in the order they are counted
+const (
+	ResultCreated   = "created"
+	ResultUpdated   = "updated"
+	ResultUnchanged = "unchanged"
+	ResultMoved     = "moved"
+	ResultDeleted   = "deleted"
+	ResultSkipped   = "skipped"
+	ResultFailed    = "failed"
+)
+
+var reportResults = []string{ResultCreated, ResultUpdated, ResultUnchanged, ResultMoved,
+	ResultDeleted, ResultSkipped, ResultFailed}
+
+// FileResult is what a run did with a file (or folder) of the repo - or with the page of a file that was removed
+type FileResult struct {
+	File   string `json:"file"` // the path from the root of the repo
+	Result string `json:"result"`
+	Title  string `json:"title,omitempty"`
+	PageID int    `json:"pageId,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the result of every file (and removed page) of a run
+type Report struct {
+	Repo        string         `json:"repo"`
+	RootPageURL string         `json:"rootPageUrl,omitempty"`
+	Counts      map[string]int `json:"counts"`
+	Files       []FileResult   `json:"files"`
+}
+
+// record method stores the result of a file - replacing any result recorded for it earlier in the run
+// pages found this run as placeholders the run created are reported as created
+func (syncer *Syncer) record(result FileResult) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	switch result.Result {
+	case ResultUpdated, ResultUnchanged, ResultMoved:
+		if syncer.placeholderIDs[result.PageID] {
+			result.Result = ResultCreated
+		}
+	}
+
+	syncer.results[result.File] = result
+}
+
+// recordPage method stores the result of the page of a file with the page ID, title and url
+func (syncer *Syncer) recordPage(fpath, result, title string, pageID int) {
+	syncer.record(FileResult{File: syncer.relativePath(fpath), Result: result, Title: title, PageID: pageID,
+		URL: syncer.pageURL(strconv.Itoa(pageID))})
+}
+
+// recordPlaceholder method notes a page created empty this run (see placeholder)
+func (syncer *Syncer) recordPlaceholder(pageID int) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	syncer.placeholderIDs[pageID] = true
+}
+
+// fileError method records the file (or folder) as failed and handles the error (see handleError)
+func (syncer *Syncer) fileError(fpath string, err error) {
+	if err == nil {
+		return
+	}
+
+	syncer.record(FileResult{File: syncer.relativePath(fpath), Result: ResultFailed, Error: err.Error()})
+
+	syncer.mu.Lock()
+	syncer.failed[syncer.managedPath(fpath)] = true
+	syncer.mu.Unlock()
+
+	syncer.handleError(err)
+}
+
+// fileFailed method returns true if the page is managed for a file (or folder) that failed this run
+// such pages are kept rather than removed as if their file was gone
+func (syncer *Syncer) fileFailed(page confluence.Page) bool {
+	managed, ok := page.Managed()
+	if !ok {
+		return false
+	}
+
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	return syncer.failed[managed.Path]
+}
+
+// recordRemoval method stores the result of removing a page managed for a file no longer in the repo
+func (syncer *Syncer) recordRemoval(page confluence.Page, err error) {
+	result := FileResult{File: page.ID, Result: ResultDeleted, Title: page.Title}
+
+	if managed, ok := page.Managed(); ok {
+		result.File = managed.Path
+	}
+
+	result.PageID, _ = strconv.Atoi(page.ID)
+
+	if err != nil {
+		result.Result, result.Error = ResultFailed, err.Error()
+	}
+
+	syncer.record(result)
+}
+
+// Report method returns the result of every file of the last run (sorted by file)
+// the files and folders left undone as the run was stopped are reported as skipped
+func (syncer *Syncer) Report() Report {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	report := Report{Repo: syncer.repoName(), Counts: map[string]int{}, Files: []FileResult{}}
+
+	if syncer.root != nil && syncer.root.id > 0 {
+		report.RootPageURL = syncer.pageURL(strconv.Itoa(syncer.root.id))
+	}
+
+	results := make(map[string]FileResult, len(syncer.results))
+
+	for file, result := range syncer.results {
+		results[file] = result
+	}
+
+	for _, path := range syncer.skipped {
+		file := syncer.relativePath(path)
+		if _, ok := results[file]; !ok {
+			results[file] = FileResult{File: file, Result: ResultSkipped}
+		}
+	}
+
+	for _, result := range results {
+		report.Counts[result.Result]++
+		report.Files = append(report.Files, result)
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].File < report.Files[j].File
+	})
+
+	return report
+}
+
+// Only method returns the report with only the files with one of the results
+func (report Report) Only(results ...string) Report {
+	only := Report{Repo: report.Repo, RootPageURL: report.RootPageURL, Counts: map[string]int{}, Files: []FileResult{}}
+
+	for _, result := range report.Files {
+		for _, want := range results {
+			if result.Result == want {
+				only.Counts[result.Result]++
+				only.Files = append(only.Files, result)
+			}
+		}
+	}
+
+	return only
+}
+
+// Failed method returns the number of files that failed
+func (report Report) Failed() int {
+	return report.Counts[ResultFailed]
+}
+
+// Pages method returns the url of the page of each file synced (by the path from the root of the repo)
+func (report Report) Pages() map[string]string {
+	pages := map[string]string{}
+
+	for _, result := range report.Files {
+		if result.URL != "" && result.Result != ResultDeleted && result.Result != ResultFailed {
+			pages[result.File] = result.URL
+		}
+	}
+
+	return pages
+}
+
+// Summary method returns the number of files with each result on one line
+func (report Report) Summary() string {
+	counts := make([]string, 0, len(reportResults))
+
+	for _, result := range reportResults {
+		counts = append(counts, fmt.Sprintf("%d %s", report.Counts[result], result))
+	}
+
+	return strings.Join(counts, ", ")
+}
+
+// PrintMarkdown method writes the report as a markdown table to w (e.g. the github step summary)
+func (report Report) PrintMarkdown(w io.Writer) error {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "## markdown-to-confluence: %s\n\n", report.Repo)
+
+	if report.RootPageURL != "" {
+		fmt.Fprintf(builder, "Root page: %s\n\n", report.RootPageURL)
+	}
+
+	fmt.Fprintf(builder, "**%s**\n\n", report.Summary())
+
+	if len(report.Files) > 0 {
+		builder.WriteString("| File | Result | Page | Error |\n| --- | --- | --- | --- |\n")
+
+		for _, result := range report.Files {
+			page := markdownCell(result.Title)
+			if result.URL != "" && result.Result != ResultDeleted {
+				page = fmt.Sprintf("[%s](%s)", page, result.URL)
+			}
+
+			fmt.Fprintf(builder, "| %s | %s | %s | %s |\n", markdownCell(result.File), result.Result, page,
+				markdownCell(result.Error))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// markdownCell function returns the text escaped for a cell of a markdown table
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// WriteJSON method writes the report as json to the file path provided
+func (report Report) WriteJSON(path string) error {
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("report json marshal error: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Clean(path), append(reportJSON, '\n'), 0o600)
+	if err != nil {
+		return fmt.Errorf("write report error: %w", err)
+	}
+
+	return nil
+}
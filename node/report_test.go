@@ -0,0 +1,122 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+	markdown "github.com/xiatechs/markdown-to-confluence/markdown"
+)
+
+// TestReport syncs testfolder twice - the first run creates every page, the second run fails to write
+// the page edited by hand since (which is kept rather than removed) and leaves the other pages
+// and the image (identical to the attachment uploaded by the first run so not uploaded again) unchanged
+func TestReport(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID, Full: true,
+		Markdown: markdown.Options{BaseURL: "https://confluence", Space: "SPACE"}}, server.Client())
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	report := syncer.Report()
+
+	root, _ := server.PageByTitle("INDEX readme (testfolder)")
+	hello, _ := server.PageByTitle("file within readme! (testfolder/file/downhere)")
+
+	assert.Equal(t, "https://confluence/spaces/SPACE/pages/"+strconv.Itoa(root.ID), report.RootPageURL)
+	assert.Equal(t, map[string]int{ResultCreated: 4}, report.Counts)
+	assert.Equal(t, FileResult{File: "file/downhere/hello.md", Result: ResultCreated,
+		Title: "file within readme! (testfolder/file/downhere)", PageID: hello.ID,
+		URL: "https://confluence/spaces/SPACE/pages/" + strconv.Itoa(hello.ID)}, report.Files[0])
+	assert.Len(t, report.Pages(), 4)
+
+	_, err := server.Client().UpdatePage(context.Background(), hello.ID, 0, int64(hello.Version),
+		&markdown.FileContents{MetaData: map[string]interface{}{"title": hello.Title},
+			Body: []byte("<p>edited by hand</p>")}, confluence.PageResults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Fail(http.MethodPut, "/rest/api/content/"+strconv.Itoa(hello.ID), http.StatusBadRequest, 1)
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	report = syncer.Report()
+
+	assert.Equal(t, map[string]int{ResultUnchanged: 3, ResultFailed: 1}, report.Counts)
+	assert.Equal(t, 1, report.Failed())
+	assert.Equal(t, "file/downhere/hello.md", report.Files[0].File)
+	assert.Equal(t, ResultFailed, report.Files[0].Result)
+	assert.Contains(t, report.Files[0].Error, "create/update page error")
+	assert.Len(t, report.Pages(), 3)
+
+	kept, _ := server.Page(hello.ID)
+	assert.Equal(t, confluencetest.StatusCurrent, kept.Status)
+
+	var summary bytes.Buffer
+
+	assert.NoError(t, report.PrintMarkdown(&summary))
+	assert.Contains(t, summary.String(), "**0 created, 0 updated, 3 unchanged, 0 moved, 0 deleted, 0 skipped, 1 failed**")
+	assert.Contains(t, summary.String(), "| file/downhere/hello.md | failed |  | ")
+
+	file := filepath.Join(t.TempDir(), "report.json")
+
+	assert.NoError(t, report.WriteJSON(file))
+
+	contents, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written Report
+
+	assert.NoError(t, json.Unmarshal(contents, &written))
+	assert.Equal(t, report, written)
+}
+
+// TestReport_Removed checks a page removed as its file is gone is reported as deleted
+func TestReport_Removed(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "org/repo")
+
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID}, server.Client())
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	root, _ := server.PageByTitle("INDEX readme (testfolder)")
+	goneID := server.AddPage(root.ID, "gone", "<p>gone</p>")
+
+	err := server.Client().SetProperty(context.Background(), goneID, confluence.ManagedPropertyKey,
+		confluence.ManagedProperty{Repo: "org/repo", Path: "gone.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	report := syncer.Report().Only(ResultDeleted)
+
+	assert.Equal(t, []FileResult{{File: "gone.md", Result: ResultDeleted, Title: "gone", PageID: goneID}}, report.Files)
+	assert.Equal(t, map[string]int{ResultDeleted: 1}, report.Counts)
+	assert.Empty(t, report.Pages())
+}
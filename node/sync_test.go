@@ -0,0 +1,108 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/confluence/test/confluencetest"
+)
+
+// TestStartSync syncs testfolder to a fake confluence and checks the pages it ends up with
+func TestStartSync(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	syncer := NewSyncer(Options{ProjectPath: "testfolder", ParentID: masterID}, server.Client())
+
+	expected := "INDEX readme (testfolder)\n" +
+		"  a deeply nested readme file (testfolder/file/downhere)\n" +
+		"    file within readme! (testfolder/file/downhere)\n"
+
+	for run := 1; run <= 2; run++ { // the second run finds the pages the first run created
+		assert.True(t, syncer.Start(context.Background()))
+		assert.NoError(t, syncer.Delete())
+
+		assert.Equal(t, expected, server.Tree(masterID), "run %d", run)
+		assert.Len(t, server.Pages(), 4, "run %d", run)
+	}
+
+	for _, page := range server.Pages() {
+		if page.ID != masterID {
+			assert.Contains(t, page.Properties, confluence.ManagedPropertyKey, page.Title)
+		}
+	}
+
+	folder, _ := server.PageByTitle("a deeply nested readme file (testfolder/file/downhere)")
+	if len(folder.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment but found %d", len(folder.Attachments))
+	}
+
+	assert.Equal(t, "picture.jpg", folder.Attachments[0].Title)
+	assert.Equal(t, 1, folder.Attachments[0].Version)
+}
+
+// TestStartSync_TitleTemplate syncs testfolder with a title template set in the options
+func TestStartSync_TitleTemplate(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	masterID := server.AddPage(0, "master", "")
+
+	options := Options{ProjectPath: "testfolder", ParentID: masterID, TitleTemplate: "{{.Title}} - {{.Path}}"}
+
+	syncer := NewSyncer(options, server.Client())
+
+	assert.True(t, syncer.Start(context.Background()))
+	assert.NoError(t, syncer.Delete())
+
+	assert.Equal(t, "INDEX readme - readme.md\n"+
+		"  a deeply nested readme file - file/downhere/readme.md\n"+
+		"    file within readme! - file/downhere/hello.md\n", server.Tree(masterID))
+
+	options.TitleTemplate = "{{.Nope}}"
+
+	assert.False(t, NewSyncer(options, server.Client()).Start(context.Background()))
+}
+
+// TestSyncers runs two syncs of testfolder at once under different parent pages
+// each Syncer keeps its own pages so neither sees the pages of the other
+func TestSyncers(t *testing.T) {
+	server := confluencetest.NewServer("SPACE")
+	defer server.Close()
+
+	first := server.AddPage(0, "first", "")
+	second := server.AddPage(0, "second", "")
+
+	syncers := []*Syncer{
+		NewSyncer(Options{ProjectPath: "testfolder", ParentID: first, TitleTemplate: "first {{.Title}}"},
+			server.Client()),
+		NewSyncer(Options{ProjectPath: "testfolder", ParentID: second, TitleTemplate: "second {{.Title}}"},
+			server.Client()),
+	}
+
+	var wg sync.WaitGroup
+
+	for _, syncer := range syncers {
+		wg.Add(1)
+
+		go func(syncer *Syncer) {
+			defer wg.Done()
+
+			assert.True(t, syncer.Start(context.Background()))
+			assert.NoError(t, syncer.Delete())
+		}(syncer)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, "first INDEX readme\n  first a deeply nested readme file\n    first file within readme!\n",
+		server.Tree(first))
+	assert.Equal(t, "second INDEX readme\n  second a deeply nested readme file\n    second file within readme!\n",
+		server.Tree(second))
+	assert.Len(t, server.Pages(), 8)
+}
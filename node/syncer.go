@@ -0,0 +1,282 @@
+package node
+
+// syncer - a sync of a repo to confluence: its settings, its confluence client and everything it keeps track of
+// while it runs (its pages, workers and counters) so more than one sync can run in a process
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/confluence"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+	"github.com/xiatechs/markdown-to-confluence/semaphore"
+)
+
+// Options are the settings a Syncer syncs a repo with
+type Options struct {
+	ProjectPath     string           // the folder of the repo that is synced
+	ParentID        int              // the page the root page of the repo is made under (0 for the top of the space)
+	OnlyDocs        bool             // only sync the docs folders of the repo
+	TitleTemplate   string           // the template page titles are made from - overrides the repo config if set
+	DuplicateTitles string           // what happens to duplicate titles (fail, suffix or warn) - overrides the repo config if set
+	Labels          []string         // labels added to every page the sync manages
+	ManifestFile    string           // a file the manifest of the synced pages is also read from and written to (optional)
+	Full            bool             // render every page rather than only the pages of the files changed since the last sync
+	Markdown        markdown.Options // how pages are rendered (and the confluence base url and space they are linked in)
+}
+
+// Syncer syncs the repo of its options to confluence with its API client
+// and keeps the state of the run: the pages synced, the goroutines working on confluence and the counters
+type Syncer struct {
+	options Options
+	client  APIClienter // the confluence API client (or a Planner)
+	root    *Node       // the node of the root folder of the repo once the sync has started
+
+	wg                  *semaphore.Semaphore // for controlling number of goroutines
+	numberOfFolders     float64              // for counting number of folders in repo
+	foldersWithMarkdown float64              // for counting number of folders with markdown in repo
+	rootDir             string               // will contain the root folderpath of the repo
+	projectRoot         string               // the project path the sync was started with
+	repoConfig          *config.Config       // which folders and files are published (.mtc.yaml)
+	tree                *Tree                // the pages created and their confluence page IDs
+
+	runCtx     context.Context    // once done no new files or folders are started
+	requestCtx context.Context    // once done confluence requests in flight are abandoned
+	abortRun   context.CancelFunc // stops the run (see abort)
+
+	mu           sync.Mutex // protects skipped, deleteErrors and abortErr (and the results of the report)
+	skipped      []string   // files and folders not synced because the run was stopped
+	deleteErrors []error    // pages that could not be removed this run
+	abortErr     error      // the error the run was aborted for
+
+	retitled     map[string]string                  // the titles the preflight gave duplicate titles (by path from the repo root)
+	manifest     map[string]confluence.ManifestPage // the pages synced by the last run (see manifest)
+	renames      map[string]string                  // the files renamed since the last sync (see rename)
+	renameRootID int                                // the page the renamed pages are searched for under
+	managedOnce  sync.Once                          // the managed pages are only listed once per run
+	managedPages []confluence.Page                  // every managed page under renameRootID
+	managedErr   error                              // the error listing them - returned for every renamed file
+	lastSynced   string                             // the last commit of the repo synced (see incremental)
+	changes      *repoChanges                       // the files changed since lastSynced
+	unchanged    map[string]*plannedPage            // the pages left untouched this run
+
+	results        map[string]FileResult // what the run did with each file (see Report) - protected by mu
+	placeholderIDs map[int]bool          // the pages the run created empty (see placeholder) - protected by mu
+	failed         map[string]bool       // the managed paths of the files that failed - protected by mu
+}
+
+// NewSyncer function returns a Syncer for the repo and settings of the options using the confluence API client
+// (the client can be nil to only render and validate the files of the repo)
+func NewSyncer(options Options, client APIClienter) *Syncer {
+	syncer := &Syncer{
+		options:    options,
+		client:     client,
+		wg:         semaphore.NewSemaphore(numberOfRoutines),
+		requestCtx: context.Background(),
+	}
+
+	syncer.reset()
+
+	return syncer
+}
+
+// reset method forgets everything an earlier run of the Syncer kept track of
+func (syncer *Syncer) reset() {
+	syncer.root = nil
+	syncer.numberOfFolders, syncer.foldersWithMarkdown = 0, 0
+	syncer.repoConfig = config.Default(".")
+	syncer.tree = &Tree{
+		mapSem:      make(chan struct{}, 1),
+		branches:    make(map[string]string),
+		attachments: make(map[int]map[string]bool),
+		pages:       make(map[string]confluence.ManifestPage),
+	}
+
+	syncer.runCtx, syncer.abortRun = context.Background(), func() {}
+	syncer.skipped, syncer.deleteErrors, syncer.abortErr = nil, nil, nil
+
+	syncer.retitled = map[string]string{}
+	syncer.manifest = map[string]confluence.ManifestPage{}
+	syncer.renames = map[string]string{}
+	syncer.renameRootID = 0
+	syncer.managedOnce = sync.Once{}
+	syncer.managedPages, syncer.managedErr = nil, nil
+	syncer.lastSynced = ""
+	syncer.changes = nil
+	syncer.unchanged = map[string]*plannedPage{}
+
+	syncer.results = map[string]FileResult{}
+	syncer.placeholderIDs = map[int]bool{}
+	syncer.failed = map[string]bool{}
+}
+
+// SetAPIClient method sets the confluence API client (or Planner) the Syncer uses from now on
+func (syncer *Syncer) SetAPIClient(client APIClienter) {
+	syncer.client = client
+}
+
+// SetRequestContext method sets the context confluence requests are sent with
+// (by default requests are only stopped by the per-request timeout)
+func (syncer *Syncer) SetRequestContext(ctx context.Context) {
+	syncer.requestCtx = ctx
+}
+
+// ShrinkConcurrency method halves the number of goroutines allowed to work on confluence at once
+// it is called when confluence starts rate limiting requests
+func (syncer *Syncer) ShrinkConcurrency() {
+	log.Printf("confluence is throttling requests - reducing concurrency to [%d]", syncer.wg.Shrink())
+}
+
+// GrowConcurrency method allows one more goroutine to work on confluence at once (up to numberOfRoutines)
+// it is called once confluence has stopped rate limiting requests for a while
+func (syncer *Syncer) GrowConcurrency() {
+	log.Printf("confluence is no longer throttling requests - concurrency is now [%d]", syncer.wg.Grow())
+}
+
+// Start method begins the generation of a tree of the repo for confluence
+// first it validates whether the project path is a folder
+// if yes then it sets the rootDir as the project path folder name
+// then plans the pages of the repo, finds (or creates empty) the page of each of them
+// and begins the recursive method generateMaster which renders and writes each page once
+// and returns bool - if true then it means pages have been created/updated/checked on confluence
+// and there is markdown content in the folder
+// once ctx is done no new files or folders are started - see Skipped for what was left undone
+// and Report for what was done with each file (errors syncing a file are reported there rather than returned)
+func (syncer *Syncer) Start(ctx context.Context) bool {
+	syncer.reset()
+
+	syncer.runCtx, syncer.abortRun = context.WithCancel(ctx)
+
+	node := newNode(syncer)
+	node.images = map[string]bool{}
+
+	syncer.root = node
+
+	/*
+		FOR RELATIVE FILE LINKS IN CONFLUENCE...
+
+		relative links between pages are resolved with the confluence page IDs in the tree branches
+		so the sync runs in steps, each page being rendered and written once:
+
+		- plan the pages the repo makes and their titles (and check the titles with the preflight)
+		- find the page of each of them (from the manifest of the last run, or by title) & create those not found empty
+		- render and write every page with the IDs of all the pages known
+		  (or only the pages of the files changed since the last synced commit - see planUnchanged)
+	*/
+
+	projectPath := syncer.options.ProjectPath
+
+	if !isFolder(projectPath) {
+		return false
+	}
+
+	config, err := config.Load(projectPath, syncer.options.OnlyDocs)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	if syncer.options.TitleTemplate != "" { // the title template of the options overrides the repo config
+		err = config.SetTitleTemplate(syncer.options.TitleTemplate)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+	}
+
+	if syncer.options.DuplicateTitles != "" { // as does the duplicate titles setting
+		err = config.SetDuplicateTitles(syncer.options.DuplicateTitles)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+	}
+
+	syncer.repoConfig = config
+
+	syncer.numberOfFolders++
+
+	node.masterID = syncer.options.ParentID
+
+	node.path = projectPath
+
+	syncer.projectRoot = projectPath
+
+	syncer.rootDir = strings.ReplaceAll(projectPath, `/github/workspace/`, "")
+
+	syncer.rootDir = strings.ReplaceAll(syncer.rootDir, ".", "")
+
+	syncer.rootDir = strings.ReplaceAll(syncer.rootDir, "/", "")
+
+	pages, err := node.planPages(projectPath, true)
+	if err != nil {
+		log.Printf("plan error: %v", err)
+		return false
+	}
+
+	err = node.findRootPage(pages[0])
+	if err != nil {
+		syncer.fileError(projectPath, err)
+		return false
+	}
+
+	err = node.preflight(pages) // before any page is changed
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	err = node.createPlaceholders(pages)
+	if err != nil {
+		syncer.fileError(projectPath, err)
+		return false
+	}
+
+	syncer.planUnchanged(pages) // only the pages of the files changed since the last sync are rendered
+
+	node.generateMaster() // contains concurrency
+
+	log.Println("WAITING FOR GOROUTINES - rendering the pages with the page IDs known")
+
+	syncer.wg.Wait()
+
+	log.Println("Here are the pages I got today:")
+
+	syncer.printTree()
+
+	log.Println("FINISHED GOROUTINES - NOW CHECKING FOR DELETE")
+
+	return true
+}
+
+// printTree method prints out what has been generated
+func (syncer *Syncer) printTree() {
+	for path, id := range syncer.tree.branches {
+		log.Println(path, "|", syncer.pageURL(id))
+	}
+}
+
+// pageURL method returns the url of the confluence page with the ID
+func (syncer *Syncer) pageURL(id string) string {
+	return syncer.options.Markdown.BaseURL + "/spaces/" + syncer.options.Markdown.Space + "/pages/" + id
+}
+
+// Delete method deletes the pages that no longer have a file in the repo
+// and then any attachments on the managed pages that were not uploaded this run
+// it waits for every page removal to finish and returns an error if any of them failed
+func (syncer *Syncer) Delete() error {
+	if syncer.root == nil {
+		return nil
+	}
+
+	syncer.root.deleteBranches()
+
+	syncer.wg.Wait()
+
+	syncer.root.deleteOrphanedAttachments()
+
+	return syncer.deleteResult()
+}
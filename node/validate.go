@@ -0,0 +1,121 @@
+package node
+
+// validate - rendering files the way a sync would without contacting confluence
+// so they can be checked (or previewed) before a sync
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xiatechs/markdown-to-confluence/config"
+	"github.com/xiatechs/markdown-to-confluence/markdown"
+	"github.com/xiatechs/markdown-to-confluence/swagger"
+)
+
+// Render method renders a markdown or swagger file to the confluence storage format a sync would store
+// (files that are not swagger files are rendered as markdown)
+// relative links to other files are not resolved as the pages of those files are not known
+func (syncer *Syncer) Render(path string) (*markdown.FileContents, error) {
+	contents, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("render error: %w", err)
+	}
+
+	folder := filepath.Dir(path)
+	fileName := filepath.Base(path)
+
+	var parsedContents *markdown.FileContents
+
+	if isSwagger(fileName) {
+		parsedContents, err = swagger.ParseSwagger(syncer.options.Markdown, 0, contents, false,
+			map[string]string{}, folder, folder, fileName)
+	} else {
+		parsedContents, err = syncer.options.Markdown.ParseMarkdown(0, contents, strings.ToLower(fileName) == indexName,
+			map[string]string{}, folder, folder, fileName)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("render error: file [%s]: %w", path, err)
+	}
+
+	err = checkStorageFormat(parsedContents.Body)
+	if err != nil {
+		return nil, fmt.Errorf("render error: file [%s] does not render to valid confluence storage format: %w", path, err)
+	}
+
+	return parsedContents, nil
+}
+
+// checkStorageFormat function returns an error if the body is not xhtml confluence can parse
+// (e.g. raw html in the markdown with unclosed or mismatched tags)
+func checkStorageFormat(body []byte) error {
+	decoder := xml.NewDecoder(strings.NewReader("<body>" + string(body) + "</body>"))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Validate method renders every file the repo config of the project path of the options publishes as a page
+// and returns the number of files rendered and an error for each file that could not be rendered
+// (or for the repo config if it is not valid)
+func (syncer *Syncer) Validate() (int, []error) {
+	var (
+		rendered int
+		problems []error
+	)
+
+	projectPath := syncer.options.ProjectPath
+
+	config, err := config.Load(projectPath, syncer.options.OnlyDocs)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	err = filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if path != projectPath && !config.Folder(path) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !config.Page(path) {
+			return nil
+		}
+
+		rendered++
+
+		_, err = syncer.Render(path)
+		if err != nil {
+			problems = append(problems, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		problems = append(problems, fmt.Errorf("validate error: %w", err))
+	}
+
+	return rendered, problems
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckStorageFormat(t *testing.T) {
+	assert.NoError(t, checkStorageFormat([]byte(`<p>a&nbsp;b<br></p><ac:image><ri:attachment ri:filename="a.png" /></ac:image>`)))
+	assert.Error(t, checkStorageFormat([]byte("<p>text</div>")))
+}
+
+func TestValidate(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), "repo")
+
+	for path, contents := range map[string]string{
+		"readme.md":            "# repo\n",
+		"docs/guide.md":        "# guide\n",
+		"src/broken.md":        "# broken\n\n<div>\n\ntext</span>\n",
+		"vendor/lib/readme.md": "<div>\n",
+		"src/main.go":          "package main\n",
+	} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(folder, filepath.Dir(path)), 0o700))
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, path), []byte(contents), 0o600))
+	}
+
+	options := Options{ProjectPath: folder}
+
+	rendered, problems := NewSyncer(options, nil).Validate()
+	assert.Equal(t, 3, rendered)
+
+	if assert.Len(t, problems, 1) {
+		assert.Contains(t, problems[0].Error(), "broken.md] does not render to valid confluence storage format")
+	}
+
+	options.OnlyDocs = true
+
+	rendered, problems = NewSyncer(options, nil).Validate()
+	assert.Equal(t, 2, rendered)
+	assert.Empty(t, problems)
+
+	options.OnlyDocs = false
+
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, ".mtc.yaml"), []byte("exclude: [src]\n"), 0o600))
+
+	rendered, problems = NewSyncer(options, nil).Validate()
+	assert.Equal(t, 3, rendered) // vendor is no longer excluded
+	assert.Empty(t, problems)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(folder, ".mtc.yaml"), []byte("exclude: src\n"), 0o600))
+
+	_, problems = NewSyncer(options, nil).Validate()
+	if assert.Len(t, problems, 1) {
+		assert.Contains(t, problems[0].Error(), "[.mtc.yaml] is not valid")
+	}
+}
@@ -2,33 +2,182 @@
 package semaphore
 
 import (
+	"context"
 	"sync"
 )
 
-// Semaphore struct is for storing a sync.WaitGroup and a channel
+// Semaphore struct is for storing a sync.WaitGroup and the number of
+// operations allowed to run at once - the limit can be changed while running
 type Semaphore struct {
-	c  chan struct{}
-	wg *sync.WaitGroup
+	mu     *sync.Mutex
+	cond   *sync.Cond
+	limit  int // current number of operations allowed at once
+	max    int // the limit can never grow beyond this
+	active int // number of operations currently running
+	wg     *sync.WaitGroup
 }
 
 // NewSemaphore returns a Semaphore object
 func NewSemaphore(maxConcurrentOps int) *Semaphore {
-	return &Semaphore{make(chan struct{}, maxConcurrentOps), new(sync.WaitGroup)}
+	if maxConcurrentOps < 1 {
+		maxConcurrentOps = 1
+	}
+
+	mu := &sync.Mutex{}
+
+	return &Semaphore{
+		mu:    mu,
+		cond:  sync.NewCond(mu),
+		limit: maxConcurrentOps,
+		max:   maxConcurrentOps,
+		wg:    new(sync.WaitGroup),
+	}
 }
 
 // Add method adds a goroutine to waitgroup & adds to semphore
 func (s *Semaphore) Add() {
 	s.wg.Add(1)
-	s.c <- struct{}{}
+	s.acquire()
 }
 
 // Done method removes a goroutine from waitgroup & removes from semphore
 func (s *Semaphore) Done() {
-	<-s.c
+	s.release()
 	s.wg.Done()
 }
 
+// Go method runs fn in a new goroutine once there is room in the semaphore
+// unlike Add the caller never blocks, so it is safe to call from inside
+// an operation that is already holding a place in the semaphore
+// if ctx is done before there is room fn is not run and cancelled (if not nil) is called instead
+func (s *Semaphore) Go(ctx context.Context, fn, cancelled func()) {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		err := s.acquireContext(ctx)
+		if err != nil {
+			if cancelled != nil {
+				cancelled()
+			}
+
+			return
+		}
+
+		defer s.release()
+
+		fn()
+	}()
+}
+
 // Wait method is to wait for goroutines to finish
 func (s *Semaphore) Wait() {
 	s.wg.Wait()
 }
+
+// Limit method returns the number of operations currently allowed at once
+func (s *Semaphore) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limit
+}
+
+// SetLimit method changes the number of operations allowed at once (between 1 and the max)
+// operations already running are not interrupted if the limit shrinks below them
+func (s *Semaphore) SetLimit(limit int) {
+	s.resize(func(int) int { return limit })
+}
+
+// Shrink method halves the number of operations allowed at once and returns the new limit
+func (s *Semaphore) Shrink() int {
+	return s.resize(func(current int) int { return current / 2 }) //nolint:gomnd // halve
+}
+
+// Grow method allows one more operation to run at once and returns the new limit
+func (s *Semaphore) Grow() int {
+	return s.resize(func(current int) int { return current + 1 })
+}
+
+// resize method sets the limit to the result of fn (kept between 1 and the max)
+// and wakes up any goroutines waiting for room
+func (s *Semaphore) resize(fn func(current int) int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := fn(s.limit)
+
+	if limit < 1 {
+		limit = 1
+	}
+
+	if limit > s.max {
+		limit = s.max
+	}
+
+	s.limit = limit
+
+	s.cond.Broadcast()
+
+	return limit
+}
+
+// acquire method blocks until there is room in the semaphore
+func (s *Semaphore) acquire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.active >= s.limit {
+		s.cond.Wait()
+	}
+
+	s.active++
+}
+
+// acquireContext method blocks until there is room in the semaphore
+// or returns the context error if ctx is done first
+func (s *Semaphore) acquireContext(ctx context.Context) error {
+	stop := make(chan struct{})
+
+	go func() { // wake the waiters up when ctx is done so they can give up
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer close(stop)
+
+	for s.active >= s.limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.cond.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.active++
+
+	return nil
+}
+
+// release method frees up a place in the semaphore
+func (s *Semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+
+	s.cond.Broadcast()
+}
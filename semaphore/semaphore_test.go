@@ -0,0 +1,110 @@
+package semaphore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const waitFor = 50 * time.Millisecond // how long an operation that should be blocked is given to start
+
+// started function returns true if ch is closed (the operation started) within waitFor
+func started(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(waitFor):
+		return false
+	}
+}
+
+// TestSemaphore_ShrinkBelowActive checks the operations running are not interrupted when the limit
+// shrinks below them - and no new operation starts until fewer than the new limit are running
+func TestSemaphore_ShrinkBelowActive(t *testing.T) {
+	s := NewSemaphore(4)
+
+	for index := 0; index < 4; index++ {
+		s.Add()
+	}
+
+	assert.Equal(t, 2, s.Shrink())
+
+	ran := make(chan struct{})
+
+	s.Go(context.Background(), func() { close(ran) }, nil)
+
+	s.Done()
+	s.Done()
+	assert.False(t, started(ran)) // 2 running - the limit
+
+	s.Done()
+	assert.True(t, started(ran))
+
+	s.Done()
+	s.Wait()
+}
+
+// TestSemaphore_GrowCappedAtMax checks the limit never grows beyond the max or shrinks below 1
+func TestSemaphore_GrowCappedAtMax(t *testing.T) {
+	s := NewSemaphore(3)
+
+	s.SetLimit(1)
+	assert.Equal(t, 1, s.Shrink())
+	assert.Equal(t, 2, s.Grow())
+	assert.Equal(t, 3, s.Grow())
+	assert.Equal(t, 3, s.Grow())
+
+	s.SetLimit(10)
+	assert.Equal(t, 3, s.Limit())
+
+	s.SetLimit(0)
+	assert.Equal(t, 1, s.Limit())
+}
+
+// TestSemaphore_WaiterWakesOnResize checks an operation waiting for room starts as soon as the limit grows
+func TestSemaphore_WaiterWakesOnResize(t *testing.T) {
+	s := NewSemaphore(2)
+	s.SetLimit(1)
+	s.Add()
+
+	ran := make(chan struct{})
+
+	s.Go(context.Background(), func() { close(ran) }, nil)
+	assert.False(t, started(ran))
+
+	s.Grow()
+	assert.True(t, started(ran))
+
+	s.Done()
+	s.Wait()
+}
+
+// TestSemaphore_CancelWhileWaiting checks an operation waiting for room is given up (and cancelled called)
+// when its context is cancelled - and never runs once room is made
+func TestSemaphore_CancelWhileWaiting(t *testing.T) {
+	s := NewSemaphore(1)
+	s.Add()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran, cancelled := make(chan struct{}), make(chan struct{})
+
+	s.Go(ctx, func() { close(ran) }, func() { close(cancelled) })
+	assert.False(t, started(cancelled))
+
+	cancel()
+	assert.True(t, started(cancelled))
+
+	s.Done()
+	s.Wait()
+
+	assert.False(t, started(ran))
+
+	next := make(chan struct{})
+
+	s.Go(context.Background(), func() { close(next) }, nil) // the place given up is free again
+	assert.True(t, started(next))
+
+	s.Wait()
+}
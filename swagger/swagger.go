@@ -5,6 +5,7 @@ package swagger
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -17,11 +18,6 @@ import (
 	"github.com/xiatechs/markdown-to-confluence/markdown"
 )
 
-// GrabAuthors - do we want to collect authors?
-var (
-	GrabAuthors bool
-)
-
 // grabtitle function collects the filename of a markdown file
 // and returns it as a string
 //
@@ -30,6 +26,38 @@ func grabtitle(path string) string {
 	return strings.Split(path, "/")[len(strings.Split(path, "/"))-1]
 }
 
+// PageTitle function returns the title of a swagger page: the title set in the frontmatter,
+// otherwise the title of the api (info.title), otherwise the fallback (the file name)
+func PageTitle(content []byte, fallback string) string {
+	fmc, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(content))
+	if err == nil {
+		if title := markdown.FrontmatterTitle(fmc.FrontMatter); title != "" {
+			return title
+		}
+	}
+
+	if title := infoTitle(content); title != "" {
+		return title
+	}
+
+	return fallback
+}
+
+// infoTitle function returns the title in the info section of a swagger document - empty if there is none
+func infoTitle(content []byte) string {
+	var document struct {
+		Info struct {
+			Title string `json:"title"`
+		} `json:"info"`
+	}
+
+	if err := json.Unmarshal(content, &document); err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(document.Info.Title)
+}
+
 // newFileContents function creates a new filecontents object
 func newFileContents() *markdown.FileContents {
 	f := markdown.FileContents{}
@@ -127,8 +155,9 @@ func capGit(path string) string {
 	return output
 }
 
-// ParseSwagger function parses the swagger file and returns a filecontents object
-func ParseSwagger(rootID int, content []byte, isIndex bool,
+// ParseSwagger function parses the swagger file with the options markdown is rendered with
+// and returns a filecontents object
+func ParseSwagger(options markdown.Options, rootID int, content []byte, isIndex bool,
 	pages map[string]string, path, abs, fileName string) (*markdown.FileContents, error) {
 	r := bytes.NewReader(content)
 	f := newFileContents()
@@ -139,7 +168,7 @@ func ParseSwagger(rootID int, content []byte, isIndex bool,
 		f.MetaData = fmc.FrontMatter
 	}
 
-	f.MetaData["title"] = fileName
+	f.MetaData["title"] = PageTitle(content, fileName)
 
 	value, ok := f.MetaData["title"]
 	if !ok {
@@ -159,7 +188,7 @@ func ParseSwagger(rootID int, content []byte, isIndex bool,
 	f.Body = []byte(bodyString)
 	f.BodyRepresentation = "storage"
 
-	if GrabAuthors {
+	if options.GrabAuthors {
 		f.Body = append(f.Body, []byte(capGit(path))...)
 	}
 